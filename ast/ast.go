@@ -6,9 +6,29 @@ import (
 	"strings"
 )
 
+// KeywordVocabulary is the language String spells keywords in, whichever
+// language the source used. Set it to token.Bangla to print programs with
+// Bangla keywords.
+var KeywordVocabulary = token.English
+
+// Keyword returns the spelling of the keyword t in KeywordVocabulary.
+func Keyword(t token.TokenType) string {
+	if spelling, ok := KeywordVocabulary[t]; ok {
+		return spelling
+	}
+	return token.English[t]
+}
+
+// Node is implemented by every node of the tree. The source a node was
+// parsed from is the half-open range [Pos(), End()): Pos is the position of
+// its first character, included, and End the position just after its last
+// character, excluded. Each node type's Pos and End methods follow this
+// contract and are not documented separately.
 type Node interface { // TokenLiteral returns the literal value of the token associated with the node.
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // Pos returns the position of the first character of the node.
+	End() token.Position // End returns the position immediately after the node.
 }
 
 type Statement interface {
@@ -38,6 +58,11 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+func (rs *ReturnStatement) Pos() token.Position { return rs.Token.Span.Start }
+func (rs *ReturnStatement) End() token.Position {
+	return endOf(rs.ReturnValue, rs.Token)
+}
+
 // TokenLiteral returns the literal value of the token associated with the Program node.
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
@@ -46,6 +71,22 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+// Pos returns the position of the first statement of the Program.
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+// End returns the position immediately after the last statement of the Program.
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+
 type LetStatement struct {
 	Token token.Token // The token.LET token.
 	Name  *Identifier // The variable name being declared.
@@ -60,6 +101,17 @@ func (ls *LetStatement) TokenLiteral() string {
 // statementNode is a marker method to distinguish LetStatement as a statement.
 func (ls *LetStatement) statementNode() {}
 
+func (ls *LetStatement) Pos() token.Position { return ls.Token.Span.Start }
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	if ls.Name != nil {
+		return ls.Name.End()
+	}
+	return ls.Token.Span.End
+}
+
 type Identifier struct {
 	Token token.Token // The token.IDENT token.
 	Value string      // The identifier's name.
@@ -73,6 +125,9 @@ func (i *Identifier) TokenLiteral() string {
 // expressionNode is a marker method to distinguish Identifier as an expression.
 func (i *Identifier) expressionNode() {}
 
+func (i *Identifier) Pos() token.Position { return i.Token.Span.Start }
+func (i *Identifier) End() token.Position { return i.Token.Span.End }
+
 type ExpressionStatement struct {
 	Token      token.Token
 	Expression Expression
@@ -86,6 +141,16 @@ func (es *ExpressionStatement) TokenLiteral() string {
 // statementNode is a marker method to distinguish ExpressionStatement as a statement.
 func (es *ExpressionStatement) statementNode() {}
 
+func (es *ExpressionStatement) Pos() token.Position {
+	if es.Expression != nil {
+		return es.Expression.Pos()
+	}
+	return es.Token.Span.Start
+}
+func (es *ExpressionStatement) End() token.Position {
+	return endOf(es.Expression, es.Token)
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -100,7 +165,7 @@ func (p *Program) String() string {
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ls.TokenLiteral() + " ")
+	out.WriteString(Keyword(token.LET) + " ")
 	out.WriteString(ls.Name.String())
 	out.WriteString(" = ")
 
@@ -115,7 +180,7 @@ func (ls *LetStatement) String() string {
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(rs.TokenLiteral() + " ")
+	out.WriteString(Keyword(token.RETURN) + " ")
 
 	if rs.ReturnValue != nil {
 		out.WriteString(rs.ReturnValue.String())
@@ -151,6 +216,27 @@ func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+func (il *IntegerLiteral) Pos() token.Position { return il.Token.Span.Start }
+func (il *IntegerLiteral) End() token.Position { return il.Token.Span.End }
+
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+func (fl *FloatLiteral) Pos() token.Position { return fl.Token.Span.Start }
+func (fl *FloatLiteral) End() token.Position { return fl.Token.Span.End }
+
 type PrefixExpression struct {
 	Token    token.Token // The prefix token, e.g., '!' or '-'.
 	Operator string      // The operator, e.g., '!' or '-'.
@@ -165,6 +251,11 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Span.Start }
+func (pe *PrefixExpression) End() token.Position {
+	return endOf(pe.Right, pe.Token)
+}
+
 // String returns a string representation of the PrefixExpression node.
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
@@ -175,6 +266,26 @@ func (pe *PrefixExpression) String() string {
 	return out.String()
 }
 
+// AssignExpression assigns to a variable or to an element of an array or
+// hash, as in `x = 1`, `xs[0] += 2` or `h["k"] = v`.
+type AssignExpression struct {
+	Token    token.Token // The assignment token, e.g., '=' or '+='.
+	Target   Expression  // An *Identifier or an *IndexExpression.
+	Operator string      // The operator, e.g., '=', '+=', '-=', '*=', '/='.
+	Value    Expression  // The value being assigned.
+}
+
+// expressionNode is a marker method to distinguish AssignExpression as an expression.
+func (ae *AssignExpression) expressionNode() {}
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+func (ae *AssignExpression) Pos() token.Position { return ae.Target.Pos() }
+func (ae *AssignExpression) End() token.Position { return endOf(ae.Value, ae.Token) }
+func (ae *AssignExpression) String() string {
+	return ae.Target.String() + " " + ae.Operator + " " + ae.Value.String()
+}
+
 type InfixExpression struct {
 	Token    token.Token // The infix token, e.g., '+', '-', '*', '/'.
 	Left     Expression  // The left-hand side expression.
@@ -187,6 +298,15 @@ func (oe *InfixExpression) expressionNode() {}
 func (oe *InfixExpression) TokenLiteral() string {
 	return oe.Token.Literal
 }
+func (oe *InfixExpression) Pos() token.Position {
+	if oe.Left != nil {
+		return oe.Left.Pos()
+	}
+	return oe.Token.Span.Start
+}
+func (oe *InfixExpression) End() token.Position {
+	return endOf(oe.Right, oe.Token)
+}
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -212,9 +332,15 @@ func (b *Boolean) TokenLiteral() string {
 
 // String returns a string representation of the Boolean node.
 func (b *Boolean) String() string {
-	return b.Token.Literal
+	if b.Value {
+		return Keyword(token.TRUE)
+	}
+	return Keyword(token.FALSE)
 }
 
+func (b *Boolean) Pos() token.Position { return b.Token.Span.Start }
+func (b *Boolean) End() token.Position { return b.Token.Span.End }
+
 type IfExpression struct {
 	Token       token.Token     // The 'if' token.
 	Condition   Expression      // The condition expression.
@@ -230,16 +356,27 @@ func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IfExpression) Pos() token.Position { return ie.Token.Span.Start }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+	return endOf(ie.Condition, ie.Token)
+}
+
 // String returns a string representation of the IfExpression node.
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
-	out.WriteString("if ")
+	out.WriteString(Keyword(token.IF) + " ")
 	out.WriteString(ie.Condition.String())
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
 
 	if ie.Alternative != nil {
-		out.WriteString(" else ")
+		out.WriteString(" " + Keyword(token.ELSE) + " ")
 		out.WriteString(ie.Alternative.String())
 	}
 
@@ -249,6 +386,7 @@ func (ie *IfExpression) String() string {
 type BlockStatement struct {
 	Token      token.Token // The '{' token.
 	Statements []Statement // The statements in the block.
+	Rbrace     token.Token // The closing '}' token.
 }
 
 // statementNode is a marker method to distinguish BlockStatement as a statement.
@@ -259,6 +397,17 @@ func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
 }
 
+func (bs *BlockStatement) Pos() token.Position { return bs.Token.Span.Start }
+func (bs *BlockStatement) End() token.Position {
+	if bs.Rbrace.Span.End.IsValid() {
+		return bs.Rbrace.Span.End
+	}
+	if len(bs.Statements) > 0 {
+		return bs.Statements[len(bs.Statements)-1].End()
+	}
+	return bs.Token.Span.End
+}
+
 // String returns a string representation of the BlockStatement node.
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
@@ -270,9 +419,95 @@ func (bs *BlockStatement) String() string {
 	return out.String()
 }
 
+type WhileStatement struct {
+	Token     token.Token     // The 'while' token.
+	Condition Expression      // The loop condition.
+	Body      *BlockStatement // The loop body.
+}
+
+// statementNode is a marker method to distinguish WhileStatement as a statement.
+func (ws *WhileStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the token associated with the WhileStatement node.
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+
+func (ws *WhileStatement) Pos() token.Position { return ws.Token.Span.Start }
+func (ws *WhileStatement) End() token.Position { return ws.Body.End() }
+
+// String returns a string representation of the WhileStatement node.
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(Keyword(token.WHILE) + " ")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+	return out.String()
+}
+
+type ForStatement struct {
+	Token     token.Token     // The 'for' token.
+	Variables []*Identifier   // One or two loop variables, as in `for (x in xs)` or `for (i, x in xs)`.
+	In        token.Token     // The 'in' token.
+	Iterable  Expression      // The array, string or hash being iterated.
+	Body      *BlockStatement // The loop body.
+}
+
+// statementNode is a marker method to distinguish ForStatement as a statement.
+func (fs *ForStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the token associated with the ForStatement node.
+func (fs *ForStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *ForStatement) Pos() token.Position { return fs.Token.Span.Start }
+func (fs *ForStatement) End() token.Position { return fs.Body.End() }
+
+// String returns a string representation of the ForStatement node.
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	vars := []string{}
+	for _, v := range fs.Variables {
+		vars = append(vars, v.String())
+	}
+
+	out.WriteString(Keyword(token.FOR) + " ")
+	out.WriteString(strings.Join(vars, ", "))
+	out.WriteString(" " + Keyword(token.IN) + " ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(" ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token // The 'break' token.
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return Keyword(token.BREAK) + ";" }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Span.Start }
+func (bs *BreakStatement) End() token.Position  { return bs.Token.Span.End }
+
+type ContinueStatement struct {
+	Token token.Token // The 'continue' token.
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return Keyword(token.CONTINUE) + ";" }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Span.Start }
+func (cs *ContinueStatement) End() token.Position  { return cs.Token.Span.End }
+
 type FunctionLiteral struct {
 	Token      token.Token     // The 'fn' token.
 	Parameters []*Identifier   // The parameters of the function.
+	Defaults   []Expression    // Default values, parallel to Parameters; nil for parameters without one.
+	Rest       *Identifier     // The variadic parameter collecting surplus arguments, if any.
 	Body       *BlockStatement // The body of the function.
 }
 
@@ -284,18 +519,21 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 	return fl.Token.Literal
 }
 
+func (fl *FunctionLiteral) Pos() token.Position { return fl.Token.Span.Start }
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.Span.End
+}
+
 // String returns a string representation of the FunctionLiteral node.
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
-	}
-
-	out.WriteString(fl.TokenLiteral())
+	out.WriteString(Keyword(token.FUNCTION))
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(ParametersString(fl.Parameters, fl.Defaults, fl.Rest))
 	out.WriteString(") ")
 
 	out.WriteString(fl.Body.String())
@@ -303,10 +541,28 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// ParametersString formats a function's parameter list, such as
+// "x, y = 10, ...rest".
+func ParametersString(params []*Identifier, defaults []Expression, rest *Identifier) string {
+	out := []string{}
+	for i, p := range params {
+		if i < len(defaults) && defaults[i] != nil {
+			out = append(out, p.String()+" = "+defaults[i].String())
+		} else {
+			out = append(out, p.String())
+		}
+	}
+	if rest != nil {
+		out = append(out, "..."+rest.String())
+	}
+	return strings.Join(out, ", ")
+}
+
 type CallExpression struct {
 	Token     token.Token  // The '(' token.
 	Function  Expression   // The function being called.
 	Arguments []Expression // The arguments passed to the function.
+	Rparen    token.Token  // The closing ')' token.
 }
 
 // expressionNode is a marker method to distinguish CallExpression as an expression.
@@ -317,6 +573,14 @@ func (ce *CallExpression) TokenLiteral() string {
 	return ce.Token.Literal
 }
 
+func (ce *CallExpression) Pos() token.Position {
+	if ce.Function != nil {
+		return ce.Function.Pos()
+	}
+	return ce.Token.Span.Start
+}
+func (ce *CallExpression) End() token.Position { return closingEnd(ce.Rparen, ce.Token) }
+
 // String returns a string representation of the CallExpression node.
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
@@ -334,6 +598,21 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// SpreadExpression expands an array into separate arguments or elements,
+// as in f(...args).
+type SpreadExpression struct {
+	Token token.Token // The '...' token.
+	Value Expression  // The array being expanded.
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
+func (se *SpreadExpression) Pos() token.Position { return se.Token.Span.Start }
+func (se *SpreadExpression) End() token.Position { return endOf(se.Value, se.Token) }
+
 type StringLiteral struct {
 	Token token.Token // The token.STRING token.
 	Value string      // The string value.
@@ -346,16 +625,51 @@ func (sl *StringLiteral) TokenLiteral() string {
 func (sl *StringLiteral) String() string {
 	return sl.Token.Literal
 }
+func (sl *StringLiteral) Pos() token.Position { return sl.Token.Span.Start }
+func (sl *StringLiteral) End() token.Position { return sl.Token.Span.End }
+
+// InterpolatedString is a string such as "Hello ${name}!" that embeds
+// expressions. Its parts alternate between *StringLiteral text, which may be
+// empty, and the embedded expressions, starting and ending with text.
+type InterpolatedString struct {
+	Token token.Token  // The token.TEMPLATE_HEAD token.
+	Parts []Expression // The text and embedded expressions, in order.
+}
+
+func (is *InterpolatedString) expressionNode() {}
+func (is *InterpolatedString) TokenLiteral() string {
+	return is.Token.Literal
+}
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+	out.WriteString("\"")
+	for i, part := range is.Parts {
+		if i%2 == 0 {
+			out.WriteString(part.String())
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+	out.WriteString("\"")
+	return out.String()
+}
+func (is *InterpolatedString) Pos() token.Position { return is.Token.Span.Start }
+func (is *InterpolatedString) End() token.Position {
+	return endOf(is.Parts[len(is.Parts)-1], is.Token)
+}
 
 type ArrayLiteral struct {
 	Token    token.Token  // The '[' token.
 	Elements []Expression // The elements of the array.
+	Rbracket token.Token  // The closing ']' token.
 }
 
 func (al *ArrayLiteral) expressionNode() {}
 func (al *ArrayLiteral) TokenLiteral() string {
 	return al.Token.Literal
 }
+func (al *ArrayLiteral) Pos() token.Position { return al.Token.Span.Start }
+func (al *ArrayLiteral) End() token.Position { return closingEnd(al.Rbracket, al.Token) }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -372,15 +686,23 @@ func (al *ArrayLiteral) String() string {
 }
 
 type IndexExpression struct {
-	Token token.Token // The '[' token.
-	Left  Expression  // The expression being indexed.
-	Index Expression  // The index expression.
+	Token    token.Token // The '[' token.
+	Left     Expression  // The expression being indexed.
+	Index    Expression  // The index expression.
+	Rbracket token.Token // The closing ']' token.
 }
 
 func (ie *IndexExpression) expressionNode() {}
 func (ie *IndexExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+func (ie *IndexExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Span.Start
+}
+func (ie *IndexExpression) End() token.Position { return closingEnd(ie.Rbracket, ie.Token) }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -394,12 +716,15 @@ func (ie *IndexExpression) String() string {
 }
 
 type HashLiteral struct {
-	Token token.Token
-	Pairs map[Expression]Expression
+	Token  token.Token
+	Pairs  map[Expression]Expression
+	Rbrace token.Token // The closing '}' token.
 }
 
 func (hl *HashLiteral) expressionNode()      {}
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Span.Start }
+func (hl *HashLiteral) End() token.Position  { return closingEnd(hl.Rbrace, hl.Token) }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
@@ -414,3 +739,46 @@ func (hl *HashLiteral) String() string {
 
 	return out.String()
 }
+
+// endOf returns the end of n, falling back to the end of tok when n is
+// missing, as it is in partially parsed input.
+func endOf(n Node, tok token.Token) token.Position {
+	if n != nil {
+		return n.End()
+	}
+	return tok.Span.End
+}
+
+// closingEnd returns the end of a closing delimiter, falling back to the end
+// of the opening token when the delimiter was never read.
+func closingEnd(closing, opening token.Token) token.Position {
+	if closing.Span.End.IsValid() {
+		return closing.Span.End
+	}
+	return opening.Span.End
+}
+
+// BadStatement is a placeholder for a statement containing syntax errors.
+// It covers the tokens the parser skipped while recovering.
+type BadStatement struct {
+	From token.Token // The first token of the statement.
+	To   token.Token // The last token skipped.
+}
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return bs.From.Literal }
+func (bs *BadStatement) String() string       { return "<bad statement>" }
+func (bs *BadStatement) Pos() token.Position  { return bs.From.Span.Start }
+func (bs *BadStatement) End() token.Position  { return bs.To.Span.End }
+
+// BadExpression is a placeholder for an expression containing syntax errors.
+type BadExpression struct {
+	From token.Token // The first token of the expression.
+	To   token.Token // The token at which the error was found.
+}
+
+func (be *BadExpression) expressionNode()      {}
+func (be *BadExpression) TokenLiteral() string { return be.From.Literal }
+func (be *BadExpression) String() string       { return "<bad expression>" }
+func (be *BadExpression) Pos() token.Position  { return be.From.Span.Start }
+func (be *BadExpression) End() token.Position  { return be.To.Span.End }
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bangu/token"
+	"strings"
 	"testing"
 )
 
@@ -17,9 +18,42 @@ func TestString(t *testing.T) {
 		},
 	}
 
-	if program.String() != "Let myVar = anotherVar;" {
+	if program.String() != "let myVar = anotherVar;" {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 
 	}
 
 }
+
+func TestFprint(t *testing.T) {
+	pos := func(column int) token.Span {
+		return token.Span{Start: token.Position{Line: 1, Column: column}}
+	}
+	node := &InfixExpression{
+		Token:    token.Token{Type: token.PLUS, Literal: "+", Span: pos(3)},
+		Left:     &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1", Span: pos(1)}, Value: 1},
+		Operator: "+",
+		Right: &CallExpression{
+			Token:     token.Token{Type: token.LPAREN, Literal: "(", Span: pos(6)},
+			Function:  &Identifier{Token: token.Token{Type: token.IDENT, Literal: "f", Span: pos(5)}, Value: "f"},
+			Arguments: []Expression{},
+		},
+	}
+
+	expected := `InfixExpression 1:1
+  Left: IntegerLiteral 1:1
+    Value: 1
+  Operator: "+"
+  Right: CallExpression 1:5
+    Function: Identifier 1:5
+      Value: "f"
+    Arguments: [0]
+`
+	var out strings.Builder
+	if err := Fprint(&out, node); err != nil {
+		t.Fatalf("Fprint returned error: %s", err)
+	}
+	if out.String() != expected {
+		t.Errorf("wrong output.\nwant=%s\ngot=%s", expected, out.String())
+	}
+}
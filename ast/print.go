@@ -0,0 +1,118 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"bangu/token"
+)
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	tokenType = reflect.TypeOf(token.Token{})
+)
+
+// Fprint writes the tree rooted at node to w, one field per line and each
+// child indented below its parent, as in
+//
+//	InfixExpression 1:1
+//	  Left: IntegerLiteral 1:1
+//	    Value: 1
+//	  Operator: "+"
+//	  Right: IntegerLiteral 1:5
+//	    Value: 2
+//
+// Each node is shown with its type and position. Tokens are left out; the
+// fields derived from them, such as Operator, are shown instead.
+func Fprint(w io.Writer, node Node) error {
+	p := &printer{w: w}
+	p.value(reflect.ValueOf(&node).Elem(), 0)
+	return p.err
+}
+
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+// value prints v after the label already written on a line indented to
+// depth, followed by its fields or elements one level deeper.
+func (p *printer) value(v reflect.Value, depth int) {
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			p.printf("nil\n")
+			return
+		}
+	}
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		p.node(v, depth)
+	case reflect.Slice:
+		p.printf("[%d]\n", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			p.printf("%s%d: ", indent(depth+1), i)
+			p.value(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		p.printf("[%d]\n", v.Len())
+		for _, key := range sortedNodes(v.MapKeys()) {
+			p.printf("%sKey: ", indent(depth+1))
+			p.value(key, depth+1)
+			p.printf("%sValue: ", indent(depth+1))
+			p.value(v.MapIndex(key), depth+1)
+		}
+	case reflect.String:
+		p.printf("%q\n", v.String())
+	default:
+		p.printf("%v\n", v.Interface())
+	}
+}
+
+// node prints the type and position of the node v points to, then its
+// fields.
+func (p *printer) node(v reflect.Value, depth int) {
+	name := v.Elem().Type().Name()
+	if pos := v.Interface().(Node).Pos(); pos.IsValid() {
+		p.printf("%s %s\n", name, pos)
+	} else {
+		p.printf("%s\n", name)
+	}
+
+	s := v.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		field := s.Type().Field(i)
+		if !field.IsExported() || field.Type == tokenType {
+			continue
+		}
+		p.printf("%s%s: ", indent(depth+1), field.Name)
+		p.value(s.Field(i), depth+1)
+	}
+}
+
+// sortedNodes sorts the keys of a map of nodes, such as the pairs of a
+// HashLiteral, into source order.
+func sortedNodes(keys []reflect.Value) []reflect.Value {
+	if len(keys) > 0 && keys[0].Type().Implements(nodeType) {
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].Interface().(Node).Pos().Offset < keys[j].Interface().(Node).Pos().Offset
+		})
+	}
+	return keys
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
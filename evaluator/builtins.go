@@ -3,9 +3,15 @@ package evaluator
 import (
 	"bangu/object"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"unicode/utf8"
 )
 
+// Stdout is where puts writes.
+var Stdout io.Writer = os.Stdout
+
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
@@ -108,9 +114,19 @@ var builtins = map[string]*object.Builtin{
 	"puts": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(Stdout, arg.Inspect())
 			}
 			return NULL
 		},
 	},
 }
+
+// BuiltinNames returns the names of the builtin functions, sorted.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -3,16 +3,37 @@ package evaluator
 import (
 	"bangu/ast"
 	"bangu/object"
+	"bangu/token"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
+// Eval evaluates node in env. A runtime error is given the span of the
+// innermost node it arose in.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := eval(node, env)
+	if err, ok := result.(*object.Error); ok && !err.Span.Start.IsValid() {
+		err.Span = spanOf(node)
+	}
+	return result
+}
+
+func spanOf(node ast.Node) token.Span {
+	return token.Span{Start: node.Pos(), End: node.End()}
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch n := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -22,39 +43,62 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: n.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: n.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(n.Value)
 	case *ast.PrefixExpression:
 		right := Eval(n.Right, env)
-		if isError(right) {
+		if interrupts(right) {
 			return right
 		}
 		return evalPrefixExpression(n.Operator, right)
 	case *ast.InfixExpression:
+		if n.Operator == "&&" || n.Operator == "||" {
+			return evalLogicalExpression(n, env)
+		}
 		left := Eval(n.Left, env)
-		if isError(left) {
+		if interrupts(left) {
 			return left
 		}
 		right := Eval(n.Right, env)
-		if isError(right) {
+		if interrupts(right) {
 			return right
 		}
 		return evalInfixExpression(n.Operator, left, right)
+	case *ast.AssignExpression:
+		return evalAssignExpression(n, env)
 	case *ast.BlockStatement:
 		return evalBlockStatements(n, env)
 	case *ast.IfExpression:
 		return evalIfExpression(n, env)
 	case *ast.ReturnStatement:
-		val := Eval(n.ReturnValue, env)
-		if isError(val) {
+		var val object.Object
+		if call, ok := n.ReturnValue.(*ast.CallExpression); ok {
+			val = evalTailCall(call, env)
+		} else {
+			val = Eval(n.ReturnValue, env)
+		}
+		if interrupts(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.WhileStatement:
+		return evalWhileStatement(n, env)
+	case *ast.ForStatement:
+		return evalForStatement(n, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	case *ast.LetStatement:
 		val := Eval(n.Value, env)
-		if isError(val) {
+		if interrupts(val) {
 			return val
 		}
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			fn.Name = n.Name.Value
+		}
 		env.Set(n.Name.Value, val)
 		return NULL
 	case *ast.Identifier:
@@ -62,37 +106,36 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	case *ast.StringLiteral:
 		return &object.String{Value: n.Value}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(n, env)
 
 	case *ast.FunctionLiteral:
 		params := n.Parameters
 		body := n.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{Parameters: params, Defaults: n.Defaults, Rest: n.Rest, Env: env, Body: body}
+	case *ast.SpreadExpression:
+		return newError("spread operator ... is only allowed in calls and array literals")
 	case *ast.CallExpression:
-		function := Eval(n.Function, env)
-		if isError(function) {
-			return function
-		}
-		args := evalExpressions(n.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		function, args, err := evalCall(n, env)
+		if err != nil {
+			return err
 		}
-
-		return applyFunction(function, args)
+		return applyFunction(function, args, n)
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(n.Elements, env)
-		if len(elements) == 1 && isError(elements[0]) {
+		if len(elements) == 1 && interrupts(elements[0]) {
 			return elements[0]
 		}
 		return &object.Array{Elements: elements}
 
 	case *ast.IndexExpression:
 		left := Eval(n.Left, env)
-		if isError(left) {
+		if interrupts(left) {
 			return left
 		}
 		index := Eval(n.Index, env)
-		if isError(index) {
+		if interrupts(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
@@ -100,6 +143,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.HashLiteral:
 		return evalHashLiteral(n, env)
 
+	case *ast.BadStatement:
+		return newError("cannot evaluate invalid statement at %s", n.Pos())
+	case *ast.BadExpression:
+		return newError("cannot evaluate invalid expression at %s", n.Pos())
+
 	}
 
 	return NULL
@@ -113,9 +161,20 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
+			if tail, ok := result.Value.(*object.TailCall); ok {
+				return applyTailCall(tail)
+			}
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break:
+			err := newError("break statement outside loop")
+			err.Span = spanOf(statement)
+			return err
+		case *object.Continue:
+			err := newError("continue statement outside loop")
+			err.Span = spanOf(statement)
+			return err
 		}
 	}
 	return result
@@ -150,6 +209,8 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalBitwiseNotPrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -169,12 +230,27 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return evalIntegerNegation(right.Value)
+	case *object.BigInteger:
+		return normalizeBigInteger(new(big.Int).Neg(right.Value))
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
 
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+func evalBitwiseNotPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInteger:
+		return normalizeBigInteger(new(big.Int).Not(right.Value))
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
 }
 
 func evalInfixExpression(
@@ -182,6 +258,11 @@ func evalInfixExpression(
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, toBigInt(left), toBigInt(right))
+	case isNumber(left) && isNumber(right):
+		// Mixed integer and Float operands are promoted to Float.
+		return evalFloatInfixExpression(operator, left, right)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
@@ -197,24 +278,88 @@ func evalInfixExpression(
 	}
 }
 
+// evalLogicalExpression evaluates && and ||. The right operand is only
+// evaluated when the left one does not decide the result, and the result is
+// whichever operand decided it, so `name || "anonymous"` picks a fallback.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if interrupts(left) {
+		return left
+	}
+
+	if isTruthy(left) == (node.Operator == "||") {
+		return left
+	}
+	return Eval(node.Right, env)
+}
+
 func evalIntegerInfixExpression(
 	operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	switch operator {
+	case "+", "-", "*", "/", "%", "**", "<<", ">>":
+		return evalIntegerArithmetic(operator, leftVal, rightVal)
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "&^":
+		return &object.Integer{Value: leftVal &^ rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+
+}
+
+func evalFloatInfixExpression(
+	operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return &object.Float{Value: leftVal + rightVal}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return &object.Float{Value: leftVal - rightVal}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return &object.Float{Value: leftVal * rightVal}
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -223,12 +368,30 @@ func evalIntegerInfixExpression(
 		return newError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
+}
 
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat converts a numeric object to a float64.
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
 }
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
-	if isError(condition) {
+	if interrupts(condition) {
 		return condition
 	}
 
@@ -260,21 +423,143 @@ func evalBlockStatements(block *ast.BlockStatement, env *object.Environment) obj
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 
-		if result != nil && result.Type() == object.RETURN_VALUE_OBJ {
-			return result
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
+				return result
+			}
 		}
 	}
 
 	return result
 }
 
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if interrupts(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		// As in a for loop, each iteration has a scope of its own, so a let
+		// in the body neither clobbers nor outlives the outer bindings.
+		if result, done := evalLoopBody(ws.Body, object.NewEnclosedEnvironment(env)); done {
+			return result
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if interrupts(iterable) {
+		return iterable
+	}
+
+	// Each iteration binds a key and a value. With a single loop variable
+	// the variable takes the value, except for hashes where it takes the key.
+	var keys, values []object.Object
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		values = iterable.Elements
+		for i := range values {
+			keys = append(keys, &object.Integer{Value: int64(i)})
+		}
+	case *object.String:
+		for _, r := range iterable.Value {
+			keys = append(keys, &object.Integer{Value: int64(len(values))})
+			values = append(values, &object.String{Value: string(r)})
+		}
+	case *object.Hash:
+		for _, pair := range sortedPairs(iterable) {
+			keys = append(keys, pair.Key)
+			values = append(values, pair.Value)
+		}
+		if len(fs.Variables) == 1 {
+			values = keys
+		}
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	// The loop variables are bound in a scope of their own for each
+	// iteration, so they neither clobber nor outlive bindings of the same
+	// name, and closures made in the body keep the values of their
+	// iteration. Use assignment to update variables from outside the loop.
+	for i := range values {
+		iterationEnv := object.NewEnclosedEnvironment(env)
+		if len(fs.Variables) == 2 {
+			iterationEnv.Set(fs.Variables[0].Value, keys[i])
+			iterationEnv.Set(fs.Variables[1].Value, values[i])
+		} else {
+			iterationEnv.Set(fs.Variables[0].Value, values[i])
+		}
+
+		if result, done := evalLoopBody(fs.Body, iterationEnv); done {
+			return result
+		}
+	}
+
+	return NULL
+}
+
+// evalLoopBody runs one iteration of a loop. It reports done when the loop
+// must stop, along with the value the loop statement evaluates to.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+	if result == nil {
+		return nil, false
+	}
+
+	switch result.Type() {
+	case object.BREAK_OBJ:
+		return NULL, true
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// sortedPairs returns the pairs of a hash in a stable order, so that
+// iterating over a hash gives the same result every time. Keys are grouped
+// by type; integers are ordered numerically and other keys by their text.
+func sortedPairs(hash *object.Hash) []object.HashPair {
+	pairs := make([]object.HashPair, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i].Key, pairs[j].Key
+		if isInteger(a) && isInteger(b) {
+			return toBigInt(a).Cmp(toBigInt(b)) < 0
+		}
+		if a.Type() != b.Type() {
+			return a.Type() < b.Type()
+		}
+		return strings.Compare(a.Inspect(), b.Inspect()) < 0
+	})
+	return pairs
+}
+
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
-func isError(obj object.Object) bool {
+// interrupts reports whether obj ends the evaluation of the expression
+// whose value it is: a runtime error, or a return, break or continue
+// statement run inside an if expression, which leaves the function or loop
+// around it rather than giving the if a value.
+func interrupts(obj object.Object) bool {
 	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+		switch obj.Type() {
+		case object.ERROR_OBJ, object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+			return true
+		}
 	}
 	return false
 }
@@ -296,20 +581,110 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	var result []object.Object
 
 	for _, e := range exps {
+		spread, isSpread := e.(*ast.SpreadExpression)
+		if isSpread {
+			e = spread.Value
+		}
+
 		evaluated := Eval(e, env)
-		if isError(evaluated) {
+		if interrupts(evaluated) {
 			return []object.Object{evaluated}
 		}
-		result = append(result, evaluated)
+
+		if !isSpread {
+			result = append(result, evaluated)
+			continue
+		}
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			return []object.Object{newError("cannot spread %s, want ARRAY", evaluated.Type())}
+		}
+		result = append(result, array.Elements...)
 	}
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// evalCall evaluates the function and arguments of a call, returning the
+// error, or the return, break or continue, that interrupts either.
+func evalCall(call *ast.CallExpression, env *object.Environment) (object.Object, []object.Object, object.Object) {
+	function := Eval(call.Function, env)
+	if interrupts(function) {
+		return nil, nil, function
+	}
+	args := evalExpressions(call.Arguments, env)
+	if len(args) == 1 && interrupts(args[0]) {
+		return nil, nil, args[0]
+	}
+	return function, args, nil
+}
+
+// evalTailCall evaluates the function and arguments of a call in tail
+// position, leaving the call itself to applyFunction.
+func evalTailCall(call *ast.CallExpression, env *object.Environment) object.Object {
+	function, args, err := evalCall(call, env)
+	if err != nil {
+		return err
+	}
+	return &object.TailCall{Function: function, Arguments: args, Call: call}
+}
+
+// applyFunction calls fn, then each call it makes in tail position in turn,
+// so that tail calls take no Go stack. A runtime error's stack trace shows
+// the call that failed and the call applyFunction was given; the tail calls
+// between them have left no frame.
+func applyFunction(fn object.Object, args []object.Object, call *ast.CallExpression) object.Object {
+	result := callFunction(fn, args, call)
+
+	for {
+		tail, ok := result.(*object.TailCall)
+		if !ok {
+			break
+		}
+		result = callFunction(tail.Function, tail.Arguments, tail.Call)
+		if err, ok := result.(*object.Error); ok {
+			if !err.Span.Start.IsValid() {
+				err.Span = spanOf(tail.Call)
+			}
+			if fn, ok := fn.(*object.Function); ok {
+				addFrame(err, fn, call)
+			}
+		}
+	}
+	return result
+}
+
+// applyTailCall makes a tail call that has no function to return from, as
+// in a return statement at the top level of a program.
+func applyTailCall(tail *object.TailCall) object.Object {
+	result := applyFunction(tail.Function, tail.Arguments, tail.Call)
+	if err, ok := result.(*object.Error); ok && !err.Span.Start.IsValid() {
+		err.Span = spanOf(tail.Call)
+	}
+	return result
+}
+
+// callFunction calls fn, returning an *object.TailCall if fn ends by
+// making a tail call.
+func callFunction(fn object.Object, args []object.Object, call *ast.CallExpression) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
+		required := requiredParameters(fn)
+		if len(args) < required || (fn.Rest == nil && len(args) > len(fn.Parameters)) {
+			return newError("wrong number of arguments: want %s, got %d (in call to %s at %s)",
+				arity(fn, required), len(args), functionName(fn), call.Pos())
+		}
+		extendedEnv, evaluated := extendFunctionEnv(fn, args)
+		if evaluated == nil {
+			evaluated = evalFunctionBody(fn.Body, extendedEnv)
+		}
+		switch evaluated.(type) {
+		case *object.Error:
+			return addFrame(evaluated, fn, call)
+		case *object.Break:
+			return newError("break statement outside loop (in %s)", functionName(fn))
+		case *object.Continue:
+			return newError("continue statement outside loop (in %s)", functionName(fn))
+		}
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
 		return fn.Fn(args...)
@@ -318,14 +693,122 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// evalFunctionBody evaluates the body of a function, or a branch of an if
+// expression whose value the function returns, like a block. A call whose
+// value the function returns is not made but returned as an
+// *object.TailCall.
+func evalFunctionBody(body *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for i, statement := range body.Statements {
+		if es, ok := statement.(*ast.ExpressionStatement); ok && i == len(body.Statements)-1 {
+			return evalTailExpression(es.Expression, env)
+		}
+		result = Eval(statement, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// evalTailExpression evaluates the expression whose value a function
+// returns.
+func evalTailExpression(e ast.Expression, env *object.Environment) object.Object {
+	switch e := e.(type) {
+	case *ast.CallExpression:
+		return evalTailCall(e, env)
+	case *ast.IfExpression:
+		condition := Eval(e.Condition, env)
+		if interrupts(condition) {
+			return condition
+		}
+		if isTruthy(condition) {
+			return evalFunctionBody(e.Consequence, env)
+		} else if e.Alternative != nil {
+			return evalFunctionBody(e.Alternative, env)
+		}
+		return NULL
+	default:
+		return Eval(e, env)
+	}
+}
+
+// addFrame records the call of fn at call in the stack trace of err, which
+// is an error returned from the call.
+func addFrame(err object.Object, fn *object.Function, call *ast.CallExpression) object.Object {
+	if err, ok := err.(*object.Error); ok {
+		err.Stack = append(err.Stack, object.Frame{Function: functionName(fn), Call: call.Pos()})
+	}
+	return err
+}
+
+// functionName returns the name used for fn in error messages.
+func functionName(fn *object.Function) string {
+	if fn.Name != "" {
+		return fn.Name
+	}
+	return "anonymous function"
+}
+
+// requiredParameters returns the number of parameters without a default.
+func requiredParameters(fn *object.Function) int {
+	for i := range fn.Parameters {
+		if i < len(fn.Defaults) && fn.Defaults[i] != nil {
+			return i
+		}
+	}
+	return len(fn.Parameters)
+}
+
+// arity describes how many arguments fn accepts, such as "2", "1 to 3" or
+// "at least 1".
+func arity(fn *object.Function, required int) string {
+	switch {
+	case fn.Rest != nil:
+		return fmt.Sprintf("at least %d", required)
+	case required < len(fn.Parameters):
+		return fmt.Sprintf("%d to %d", required, len(fn.Parameters))
+	default:
+		return fmt.Sprintf("%d", required)
+	}
+}
+
+// extendFunctionEnv binds the arguments of a call in a new environment
+// enclosed by the function's own. Missing arguments take their default
+// values, which are evaluated at call time and can refer to earlier
+// parameters; surplus arguments are collected into the rest parameter.
+func extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, object.Object) {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if paramIdx < len(args) {
+			env.Set(param.Value, args[paramIdx])
+			continue
+		}
+
+		val := Eval(fn.Defaults[paramIdx], env)
+		if interrupts(val) {
+			return nil, val
+		}
+		env.Set(param.Value, val)
 	}
 
-	return env
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		env.Set(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -370,6 +853,107 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObject.Elements[idx]
 }
 
+// evalAssignExpression evaluates `target = value` and the compound forms
+// such as `target += value`, and returns the value that was stored.
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		current, ok := env.Get(target.Value)
+		if !ok {
+			return newError("assignment to undeclared variable: %s", target.Value)
+		}
+		val := evalAssignedValue(node, current, env)
+		if interrupts(val) {
+			return val
+		}
+		env.Assign(target.Value, val)
+		return val
+
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if interrupts(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if interrupts(index) {
+			return index
+		}
+
+		var current object.Object
+		if node.Operator != "=" {
+			current = evalIndexExpression(left, index)
+			if interrupts(current) {
+				return current
+			}
+		}
+		val := evalAssignedValue(node, current, env)
+		if interrupts(val) {
+			return val
+		}
+		return evalIndexAssignment(left, index, val)
+
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+// evalAssignedValue evaluates the right side of an assignment. For compound
+// operators such as += it combines the target's current value with it.
+func evalAssignedValue(node *ast.AssignExpression, current object.Object, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if interrupts(val) || node.Operator == "=" {
+		return val
+	}
+	return evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+}
+
+func evalIndexAssignment(left, index, val object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		if idx.Value < 0 || idx.Value >= int64(len(left.Elements)) {
+			return newError("index out of range: %d (array length %d)", idx.Value, len(left.Elements))
+		}
+		left.Elements[idx.Value] = val
+		return val
+
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+		return val
+
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// evalInterpolatedString joins the text of an interpolated string with the
+// values of its embedded expressions, rendered as by Inspect.
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		if text, ok := part.(*ast.StringLiteral); ok {
+			out.WriteString(text.Value)
+			continue
+		}
+
+		val := Eval(part, env)
+		if interrupts(val) {
+			return val
+		}
+		out.WriteString(val.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
 func evalHashLiteral(
 	node *ast.HashLiteral,
 	env *object.Environment,
@@ -378,7 +962,7 @@ func evalHashLiteral(
 
 	for keyNode, valueNode := range node.Pairs {
 		key := Eval(keyNode, env)
-		if isError(key) {
+		if interrupts(key) {
 			return key
 		}
 
@@ -388,7 +972,7 @@ func evalHashLiteral(
 		}
 
 		value := Eval(valueNode, env)
-		if isError(value) {
+		if interrupts(value) {
 			return value
 		}
 
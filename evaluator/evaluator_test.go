@@ -4,6 +4,10 @@ import (
 	"bangu/lexer"
 	"bangu/object"
 	"bangu/parser"
+	"bangu/token"
+	"math"
+	"runtime/debug"
+	"strings"
 	"testing"
 )
 
@@ -59,6 +63,42 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.5", 3.5},
+		{"-2.25", -2.25},
+		{"1e3", 1000},
+		{"0.1 + 0.2 * 2", 0.5},
+		{"1.5 * 2", 3},
+		{"7 / 2.0", 3.5},
+		{"2 - 0.5", 1.5},
+		{"(1.5 + 2.5) * -1", -4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if math.Abs(result.Value-expected) > 1e-9 {
+		t.Errorf("object has wrong value. got=%g, want=%g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -83,6 +123,21 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1.5 < 2", true},
+		{"2 > 2.5", false},
+		{"1 == 1.0", true},
+		{"0.5 != 0.5", false},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+		{"1.5 <= 1.5", true},
+		{"2 >= 2.5", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 < 2 && 2 > 3", false},
+		{"1 > 2 || 2 < 3", true},
+		{"false || false", false},
+		{"1 == 1 || 1 / 0 == 0 && false", true},
 	}
 
 	for _, tt := range tests {
@@ -166,6 +221,9 @@ func TestReturnStatements(t *testing.T) {
 		{"return 2 * 5; 9;", 10},
 		{"9; return 2 * 5; 9;", 10},
 		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
+		{"let f = fn() { let x = if (true) { return 10; }; 1 }; f()", 10},
+		{"let f = fn() { [1, if (true) { return 10; }] }; f()", 10},
+		{"let f = fn() { 1 + (if (true) { return 10; }) }; f()", 10},
 	}
 
 	for _, tt := range tests {
@@ -227,6 +285,93 @@ func TestErrorHandling(t *testing.T) {
 			`{"name": "Monkey"}[fn(x) { x }];`,
 			"unusable as hash key: FUNCTION",
 		},
+		{
+			"let add = fn(x, y) { x + y }; add(1);",
+			"wrong number of arguments: want 2, got 1 (in call to add at 1:31)",
+		},
+		{
+			"let add = fn(x, y) { x + y };\nadd(1, 2, 3);",
+			"wrong number of arguments: want 2, got 3 (in call to add at 2:1)",
+		},
+		{
+			"fn() { 1 }(5)",
+			"wrong number of arguments: want 0, got 1 (in call to anonymous function at 1:1)",
+		},
+		{
+			"let f = fn(x) { x }; let g = f; g();",
+			"wrong number of arguments: want 1, got 0 (in call to f at 1:33)",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestErrorSpans(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedStart string
+		expectedEnd   string
+	}{
+		{"5 + true;", "1:1", "1:9"},
+		{"let x = 1;\nx + foobar", "2:5", "2:11"},
+		{"let f = fn(a) {\n  a / 0\n};\nf(1)", "2:3", "2:8"},
+		{"[1, 2][-true]", "1:8", "1:13"},
+		{"len(1)", "1:1", "1:7"},
+		{"1;\nbreak;", "2:1", "2:6"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if start := errObj.Span.Start.String(); start != tt.expectedStart {
+			t.Errorf("%q: wrong error start. expected=%s, got=%s", tt.input, tt.expectedStart, start)
+		}
+		if end := errObj.Span.End.String(); end != tt.expectedEnd {
+			t.Errorf("%q: wrong error end. expected=%s, got=%s", tt.input, tt.expectedEnd, end)
+		}
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"1 / 0", nil},
+		{"let f = fn(x) { x / 0 };\nf(1)", []string{"in f, called at 2:1"}},
+		{
+			"let inner = fn() { missing };\nlet outer = fn() { 1 + inner() };\nouter()",
+			[]string{"in inner, called at 2:24", "in outer, called at 3:1"},
+		},
+		{"let f = fn(x = y) { x };\nf()", []string{"in f, called at 2:1"}},
+		// Tail calls replace the frame of the function making them.
+		{"let f = fn(n) { if (n == 0) { len(1) } else { f(n - 1) } };\nf(2)", []string{"in f, called at 2:1"}},
+		{"let f = fn(n) { if (n == 0) { 1 + len(1) } else { f(n - 1) } };\nf(2)", []string{
+			"in f, called at 1:51", "in f, called at 2:1",
+		}},
+		{"let f = fn(n) { if (n == 0) { 1 + len(1) } else { 1 + f(n - 1) } };\nf(2)", []string{
+			"in f, called at 1:55", "in f, called at 1:55", "in f, called at 2:1",
+		}},
+		{"fn() { -true }()", []string{"in anonymous function, called at 1:1"}},
+		{"let f = fn(x) { x };\nf(1, 2)", nil},
 	}
 
 	for _, tt := range tests {
@@ -238,12 +383,181 @@ func TestErrorHandling(t *testing.T) {
 			continue
 		}
 
+		var stack []string
+		for _, frame := range errObj.Stack {
+			stack = append(stack, frame.String())
+		}
+		if strings.Join(stack, "; ") != strings.Join(tt.expected, "; ") {
+			t.Errorf("%q: wrong stack. expected=%q, got=%q", tt.input, tt.expected, stack)
+		}
+	}
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"1 / 0", "division by zero"},
+		{"let zero = 0; 10 / zero", "division by zero"},
+		{"1.5 / 0", "division by zero"},
+		{"1 / 0.0", "division by zero"},
+		{"1 % 0", "modulo by zero"},
+		{"1.5 % 0", "modulo by zero"},
+		{"true && 1 / 0", "division by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
 		if errObj.Message != tt.expectedMessage {
 			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
 		}
 	}
 }
 
+func TestIntegerOverflowPolicies(t *testing.T) {
+	defer func(policy OverflowPolicy) { IntegerOverflow = policy }(IntegerOverflow)
+
+	tests := []struct {
+		input    string
+		policy   OverflowPolicy
+		expected string
+	}{
+		{"9223372036854775807 + 1", OverflowWrap, "-9223372036854775808"},
+		{"9223372036854775807 + 1", OverflowError, "ERROR: integer overflow: 9223372036854775807 + 1"},
+		{"9223372036854775807 + 1", OverflowPromote, "9223372036854775808"},
+		{"-9223372036854775807 - 2", OverflowError, "ERROR: integer overflow: -9223372036854775807 - 2"},
+		{"-9223372036854775807 - 2", OverflowPromote, "-9223372036854775809"},
+		{"4294967296 * 4294967296", OverflowWrap, "0"},
+		{"4294967296 * 4294967296", OverflowError, "ERROR: integer overflow: 4294967296 * 4294967296"},
+		{"4294967296 * 4294967296", OverflowPromote, "18446744073709551616"},
+		{"let min = -9223372036854775807 - 1; min / -1", OverflowError, "ERROR: integer overflow: -9223372036854775808 / -1"},
+		{"let min = -9223372036854775807 - 1; -min", OverflowPromote, "9223372036854775808"},
+		{"(9223372036854775807 + 1) - 1", OverflowPromote, "9223372036854775807"},
+		{"(9223372036854775807 + 1) * 2 > 9223372036854775807", OverflowPromote, "true"},
+		{"(9223372036854775807 + 1) / 2.0", OverflowPromote, "4611686018427388000.0"},
+		{"(9223372036854775807 + 1) / 0", OverflowPromote, "ERROR: division by zero"},
+		{"3 * 4 + 1", OverflowError, "13"},
+		{"2 ** 64", OverflowWrap, "0"},
+		{"2 ** 64", OverflowError, "ERROR: integer overflow: 2 ** 64"},
+		{"2 ** 64", OverflowPromote, "18446744073709551616"},
+		{"(2 ** 64) ** 2 % 1000", OverflowPromote, "456"},
+		{"-(2 ** 64) % 7", OverflowPromote, "-2"},
+		{"2 ** 62", OverflowError, "4611686018427387904"},
+		{"(-2) ** 63", OverflowError, "-9223372036854775808"},
+		{"3 ** 40", OverflowError, "ERROR: integer overflow: 3 ** 40"},
+		{"1 << 63", OverflowWrap, "-9223372036854775808"},
+		{"1 << 64", OverflowWrap, "0"},
+		{"1 << 62", OverflowError, "4611686018427387904"},
+		{"1 << 63", OverflowError, "ERROR: integer overflow: 1 << 63"},
+		{"3 << 100", OverflowPromote, "3802951800684688204490109616128"},
+		{"(3 << 100) >> 99", OverflowPromote, "6"},
+		{"(1 << 70) | 1 & 1", OverflowPromote, "1180591620717411303425"},
+		{"~(1 << 70) < 0", OverflowPromote, "true"},
+		{"(1 << 70) ^ (1 << 70)", OverflowPromote, "0"},
+		{"(1 << 70) << -1", OverflowPromote, "ERROR: negative shift count: -1"},
+		{"1 << 100000000000", OverflowPromote, "ERROR: shift count too large: 100000000000"},
+		{"2 ** 100000000000", OverflowPromote, "ERROR: exponent too large: 100000000000"},
+		{"(1 << 70) ** 1000000", OverflowPromote, "ERROR: exponent too large: 1000000"},
+		{"2 ** 64", OverflowPromote, "18446744073709551616"},
+		{"(-1) ** 100000000000", OverflowPromote, "1"},
+		{"1 ** (1 << 70)", OverflowPromote, "1"},
+		{"0 ** 100000000000", OverflowPromote, "0"},
+		{"-(1 << 70) >> 100000000000", OverflowPromote, "-1"},
+	}
+
+	for _, tt := range tests {
+		IntegerOverflow = tt.policy
+		evaluated := testEval(tt.input)
+
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q with policy %s: expected=%q, got=%q",
+				tt.input, tt.policy, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	IntegerOverflow = OverflowPromote
+	if _, ok := testEval("(9223372036854775807 + 1) - 1").(*object.Integer); !ok {
+		t.Errorf("promoted result that fits in 64 bits was not normalized to Integer")
+	}
+}
+
+func TestArithmeticOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"7 % 3", "1"},
+		{"-7 % 3", "-1"},
+		{"7 % -3", "1"},
+		{"7.5 % 2", "1.5"},
+		{"2 ** 10", "1024"},
+		{"2 ** 0", "1"},
+		{"2 ** 3 ** 2", "512"},
+		{"-2 ** 2", "-4"},
+		{"2 ** -1", "0.5"},
+		{"2.0 ** 0.5 > 1.41", "true"},
+		{"4 ** 0.5", "2.0"},
+		{"2 * 3 ** 2", "18"},
+		{"10 - 7 % 4", "7"},
+		{"12 & 10", "8"},
+		{"12 | 10", "14"},
+		{"12 ^ 10", "6"},
+		{"12 &^ 10", "4"},
+		{"~5", "-6"},
+		{"1 << 4", "16"},
+		{"-16 >> 2", "-4"},
+		{"1 >> 70", "0"},
+		{"-1 >> 70", "-1"},
+		{"1 + 2 & 3", "3"},
+		{"let flags = 5; flags & 4 == 4", "true"},
+		{"1 << -1", "ERROR: negative shift count: -1"},
+		{"8 >> -2", "ERROR: negative shift count: -2"},
+		{"1.5 & 1", "ERROR: unknown operator: FLOAT & INTEGER"},
+		{"~1.5", "ERROR: unknown operator: ~FLOAT"},
+		{"~true", "ERROR: unknown operator: ~BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 && 2", "2"},
+		{"0 && 2", "2"},
+		{"false && 2", "false"},
+		{"null_value() && 2", "null"},
+		{"false || \"fallback\"", "fallback"},
+		{"\"name\" || \"fallback\"", "name"},
+		{"let calls = 0; let f = fn() { calls += 1; true }; false && f(); true || f(); calls", "0"},
+		{"let calls = 0; let f = fn() { calls += 1; true }; true && f(); false || f(); calls", "2"},
+		{"false || nope", "ERROR: identifier not found: nope"},
+		{"true || nope", "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval("let null_value = fn() { if (false) { 1 } }; " + tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -253,6 +567,27 @@ func TestLetStatements(t *testing.T) {
 		{"let a = 5 * 5; a;", 25},
 		{"let a = 5; let b = a; b;", 5},
 		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+		{"let সংখ্যা = 5; let দ্বিগুণ = fn(x) { x * 2 }; দ্বিগুণ(সংখ্যা);", 10},
+		{"let x1 = 3; let x2 = 4; x1 * x2;", 12},
+		{"let ফেরত = 3; let সত্য = 4; ফেরত * সত্য", 12},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBanglaKeywords(t *testing.T) {
+	token.UseVocabularies(token.English, token.Bangla)
+	defer token.UseVocabularies(token.English)
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"ধরি বয়স = ১৭; যদি (বয়স >= ১৮) { ১ } নাহলে { বয়স + ২ }", 19},
+		{"ধরি যোগ = কাজ(ক, খ) { ফেরত ক + খ; }; let x = যোগ(২০, 22); x", 42},
 	}
 
 	for _, tt := range tests {
@@ -303,6 +638,146 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestDefaultAndRestParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(x, y = 10) { x + y }; f(1)", "11"},
+		{"let f = fn(x, y = 10) { x + y }; f(1, 2)", "3"},
+		{"let f = fn(x, y = x * 2) { y }; f(4)", "8"},
+		{"let n = 1; let f = fn(x = n) { x }; let n = 5; f()", "5"},
+		{"let f = fn(x, ...rest) { rest }; f(1, 2, 3)", "[2, 3]"},
+		{"let f = fn(x, ...rest) { rest }; f(1)", "[]"},
+		{"let add = fn(x, y) { x + y }; let args = [1, 2]; add(...args)", "3"},
+		{"let f = fn(...all) { all }; f(0, ...[1, 2], ...[], 3)", "[0, 1, 2, 3]"},
+		{"[0, ...[1, 2], 3]", "[0, 1, 2, 3]"},
+		{"len(...[\"abc\"])", "3"},
+		{"let f = fn(x, y = 1, z = 2) { x }; f()", "ERROR: wrong number of arguments: want 1 to 3, got 0 (in call to f at 1:36)"},
+		{"let f = fn(x, ...rest) { x }; f()", "ERROR: wrong number of arguments: want at least 1, got 0 (in call to f at 1:31)"},
+		{"let f = fn(x = y) { x }; f()", "ERROR: identifier not found: y"},
+		{"let f = fn(x) { x }; f(...5)", "ERROR: cannot spread INTEGER, want ARRAY"},
+		{"...[1]", "ERROR: spread operator ... is only allowed in calls and array literals"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let i = 0; while (i < 5) { i = i + 1; }; i", "5"},
+		{"let i = 0; while (true) { i = i + 1; if (i == 3) { break; } }; i", "3"},
+		{"let n = 0; let i = 0; while (i < 5) { i = i + 1; if (i == 2) { continue; } n = n + i; }; n", "13"},
+		{"let s = 0; for (x in [1, 2, 3]) { s = s + x; }; s", "6"},
+		{"let s = []; for (i, x in [10, 20]) { s = push(s, i); s = push(s, x); }; s", "[0, 10, 1, 20]"},
+		{`let s = []; for (c in "héj") { s = push(s, c); }; s`, "[h, é, j]"},
+		{`let s = []; for (k in {"b": 2, "a": 1, "c": 3}) { s = push(s, k); }; s`, "[a, b, c]"},
+		{`let s = []; for (k, v in {3: "c", 1: "a", 2: "b"}) { s = push(s, v); }; s`, "[a, b, c]"},
+		{"let s = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break; } s = s + x; }; s", "3"},
+		{"let s = 0; for (x in [1, 2]) { for (y in [10, 20]) { if (y == 20) { break; } s = s + x * y; } }; s", "30"},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x; } } 0 }; f()", "2"},
+		{"let f = fn() { while (true) { return 7; } }; f()", "7"},
+		{"while (false) { 1 }", "null"},
+		{"break;", "ERROR: break statement outside loop"},
+		{"continue;", "ERROR: continue statement outside loop"},
+		{"let f = fn() { break; }; for (x in [1]) { f(); }", "ERROR: break statement outside loop (in f)"},
+		{"let i = 0; while (i < 3) { let x = if (true) { break }; i = i + 1; }; i", "0"},
+		{"let r = 0; while (true) { r = [if (true) { break }]; 0; }; r", "0"},
+		{"let s = 0; for (x in [1, 2, 3]) { s = s + (if (x == 2) { continue } else { x }); }; s", "4"},
+		{"let n = 0; let f = fn(x) { n = n + 1 }; for (x in [1, 2]) { f(if (true) { continue }); }; n", "0"},
+		{`let s = ""; for (x in [1, 2]) { s = "${s}${if (x == 2) { break } else { x }}"; }; s`, "1"},
+		{"let h = {}; for (x in [1, 2]) { h[if (x == 2) { break } else { x }] = x; }; h", "{1: 1}"},
+		{"let x = if (true) { break };", "ERROR: break statement outside loop"},
+		{"let f = fn(a = if (true) { continue }) { a }; for (x in [1]) { f(); }", "ERROR: continue statement outside loop (in f)"},
+		{"let v = 9; for (v in [1, 2]) { v }; v", "9"},
+		{"let s = 0; let i = 0; while (i < 3) { let s = s + i; i = i + 1; }; s", "0"},
+		{"let i = 0; while (i < 2) { let t = i; i += 1; }; t", "ERROR: identifier not found: t"},
+		{"for (v in [1, 2]) { v }; v", "ERROR: identifier not found: v"},
+		{"let fs = []; for (x in [1, 2]) { fs = push(fs, fn() { x }); }; fs[0]() + fs[1]() * 10", "21"},
+		{"for (x in 5) { x }", "ERROR: cannot iterate over INTEGER"},
+		{"while (y) { 1 }", "ERROR: identifier not found: y"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1; x = 2; x", "2"},
+		{"let x = 1; x = 2", "2"},
+		{"let x = 1; let y = 1; x = y = 5; [x, y]", "[5, 5]"},
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x", "6"},
+		{"let s = \"a\"; s += \"b\"; s", "ab"},
+		{"let x = 1.5; x *= 2; x", "3.0"},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }(); counter(); counter(); counter()", "3"},
+		{"let x = 1; let f = fn() { let x = 2; x = 3; x }; [f(), x]", "[3, 1]"},
+		{"let i = 0; let s = 0; while (i < 4) { i += 1; s += i; }; s", "10"},
+		{"let xs = [1, 2, 3]; xs[1] = 20; xs", "[1, 20, 3]"},
+		{"let xs = [1, 2, 3]; xs[2] *= 10; xs", "[1, 2, 30]"},
+		{"let xs = [1, 2]; let ys = xs; ys[0] = 9; xs", "[9, 2]"},
+		{"let grid = [[0, 0], [0, 0]]; grid[1][0] = 5; grid", "[[0, 0], [5, 0]]"},
+		{`let h = {"a": 1}; h["b"] = 2; h["a"] += 10; [h["a"], h["b"]]`, "[11, 2]"},
+		{"x = 1", "ERROR: assignment to undeclared variable: x"},
+		{"let x = 1; x += true", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let x = 1; x /= 0", "ERROR: division by zero"},
+		{"let xs = [1]; xs[1] = 2", "ERROR: index out of range: 1 (array length 1)"},
+		{"let xs = [1]; xs[-1] = 2", "ERROR: index out of range: -1 (array length 1)"},
+		{`let xs = [1]; xs["a"] = 2`, "ERROR: array index must be INTEGER, got STRING"},
+		{"let h = {}; h[fn(x) { x }] = 1", "ERROR: unusable as hash key: FUNCTION"},
+		{"let s = \"abc\"; s[0] = \"x\"", "ERROR: index assignment not supported: STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	// Without tail calls, recursion this deep would need far more stack.
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } }; count(100000)", 0},
+		{"let sum = fn(n, acc) { if (n == 0) { return acc; } return sum(n - 1, acc + n); }; sum(100000, 0)", 5000050000},
+		{`
+		let even = fn(n) { if (n == 0) { 1 } else { odd(n - 1) } };
+		let odd = fn(n) { if (n == 0) { 0 } else { even(n - 1) } };
+		even(100001)
+		`, 0},
+		{"let f = fn(n) { while (true) { if (n == 0) { return 7; } return f(n - 1); } }; f(100000)", 7},
+		{"let f = fn(n, ...rest) { if (n == 0) { len(rest) } else { f(n - 1, ...rest) } }; f(100000, 1, 2)", 2},
+		{"let f = fn(n) { n * 2 }; return f(21);", 42},
+		{"let f = fn(n) { n * 2 }; 1 + f(1)", 3},
+		{"let f = fn(n) { if (n < 2) { n } else { f(n - 1) + f(n - 2) } }; f(15)", 610},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestClosures(t *testing.T) {
 	input := `
 	let newAdder = fn(x) {
@@ -330,6 +805,49 @@ func TestStringLiteral(t *testing.T) {
 	}
 }
 
+func TestStringEscapesAndRawStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"say \"hi\"\n"`, "say \"hi\"\n"},
+		{`"\u{48}i" + "\t!"`, "Hi\t!"},
+		{"`{\"name\": \"bangu\",\n \"tags\": [\"\\n\"]}`", "{\"name\": \"bangu\",\n \"tags\": [\"\\n\"]}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("%s: object is not String. got=%T (%+v)", tt.input, evaluated, evaluated)
+		}
+		if str.Value != tt.expected {
+			t.Errorf("%s: String has wrong value. got=%q, want=%q", tt.input, str.Value, tt.expected)
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "Ada"; let age = 36; "Hello ${name}, you are ${age + 1}"`, "Hello Ada, you are 37"},
+		{`"${1.5} ${true} ${[1, "two"]} ${if (false) { 1 }}"`, "1.5 true [1, two] null"},
+		{`let h = {"k": "v"}; "${h["k"]}${ "-${h["k"]}-" }"`, "v-v-"},
+		{`"cost: \${5}"`, "cost: ${5}"},
+		{`let f = fn(x) { "<${x}>" }; f(f(1))`, "<<1>>"},
+		{`"${missing}"`, "ERROR: identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestStringConcatenation(t *testing.T) {
 	input := `"Hello, " + "World!"`
 	evaluated := testEval(input)
@@ -524,6 +1042,11 @@ func TestHashIndexExpressions(t *testing.T) {
 			`{false: 5}[false]`,
 			5,
 		},
+		{`{1: 5}[1.0]`, 5},
+		{`{1.0: 5}[1]`, 5},
+		{`{0: 5}[-0.0]`, 5},
+		{`{1.5: 5}[1.5]`, 5},
+		{`{1: 5}[1.5]`, nil},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -0,0 +1,261 @@
+package evaluator
+
+import (
+	"bangu/object"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// OverflowPolicy decides what happens when integer arithmetic overflows the
+// 64-bit range of object.Integer.
+type OverflowPolicy int
+
+const (
+	OverflowWrap    OverflowPolicy = iota // wrap around, as Go's int64 does
+	OverflowError                         // produce an error object
+	OverflowPromote                       // promote the result to an object.BigInteger
+)
+
+// IntegerOverflow is the policy applied to integer + - * ** << (and to
+// negation and division, which can overflow for math.MinInt64).
+var IntegerOverflow = OverflowWrap
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowWrap:
+		return "wrap"
+	case OverflowError:
+		return "error"
+	case OverflowPromote:
+		return "promote"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// ParseOverflowPolicy returns the policy named "wrap", "error" or "promote".
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	for _, p := range []OverflowPolicy{OverflowWrap, OverflowError, OverflowPromote} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown overflow policy %q (want wrap, error or promote)", name)
+}
+
+// evalIntegerArithmetic applies an arithmetic operator to two integers,
+// handling overflow according to IntegerOverflow.
+func evalIntegerArithmetic(operator string, leftVal, rightVal int64) object.Object {
+	var result int64
+	overflow := false
+
+	switch operator {
+	case "+":
+		result = leftVal + rightVal
+		overflow = (leftVal >= 0) == (rightVal >= 0) && (result >= 0) != (leftVal >= 0)
+	case "-":
+		result = leftVal - rightVal
+		overflow = (leftVal >= 0) != (rightVal >= 0) && (result >= 0) != (leftVal >= 0)
+	case "*":
+		result, overflow = multiply(leftVal, rightVal)
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		result = leftVal / rightVal
+		overflow = leftVal == math.MinInt64 && rightVal == -1
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		// The remainder takes the sign of the dividend, as in Go.
+		result = leftVal % rightVal
+	case "**":
+		if rightVal < 0 {
+			return &object.Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+		result, overflow = power(leftVal, rightVal)
+	case "<<":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		result = leftVal << rightVal
+		overflow = leftVal != 0 && (rightVal >= 64 || result>>rightVal != leftVal)
+	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		// An arithmetic shift, which keeps the sign and never overflows.
+		result = leftVal >> rightVal
+	default:
+		return newError("unknown operator: %s %s %s",
+			object.INTEGER_OBJ, operator, object.INTEGER_OBJ)
+	}
+
+	if !overflow {
+		return &object.Integer{Value: result}
+	}
+
+	switch IntegerOverflow {
+	case OverflowError:
+		return newError("integer overflow: %d %s %d", leftVal, operator, rightVal)
+	case OverflowPromote:
+		return evalBigIntegerInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+	default:
+		return &object.Integer{Value: result}
+	}
+}
+
+// multiply returns a * b wrapped to 64 bits and reports whether it overflowed.
+func multiply(a, b int64) (int64, bool) {
+	product := a * b
+	overflow := a != 0 && (product/a != b || (a == -1 && b == math.MinInt64))
+	return product, overflow
+}
+
+// power returns base ** exp, for exp >= 0, wrapped to 64 bits and reports
+// whether it overflowed. It squares and multiplies, so it takes O(log exp)
+// steps.
+func power(base, exp int64) (int64, bool) {
+	result, overflow := int64(1), false
+	for {
+		var o bool
+		if exp&1 == 1 {
+			result, o = multiply(result, base)
+			overflow = overflow || o
+		}
+		exp >>= 1
+		if exp == 0 {
+			return result, overflow
+		}
+		base, o = multiply(base, base)
+		overflow = overflow || o
+	}
+}
+
+// evalIntegerNegation negates an integer, handling the overflow of
+// -math.MinInt64 according to IntegerOverflow.
+func evalIntegerNegation(value int64) object.Object {
+	if value != math.MinInt64 {
+		return &object.Integer{Value: -value}
+	}
+
+	switch IntegerOverflow {
+	case OverflowError:
+		return newError("integer overflow: -(%d)", value)
+	case OverflowPromote:
+		return normalizeBigInteger(new(big.Int).Neg(big.NewInt(value)))
+	default:
+		return &object.Integer{Value: value}
+	}
+}
+
+// maxBigShift bounds the shift count for arbitrary-precision integers, so a
+// typo such as 1 << 1000000000000 fails instead of exhausting memory. It
+// bounds the bit length of the result of ** for the same reason.
+const maxBigShift = 1 << 24
+
+func evalBigIntegerInfixExpression(operator string, leftVal, rightVal *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return normalizeBigInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return normalizeBigInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return normalizeBigInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		// Quo truncates toward zero, matching object.Integer division.
+		return normalizeBigInteger(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("modulo by zero")
+		}
+		// Rem takes the sign of the dividend, matching object.Integer.
+		return normalizeBigInteger(new(big.Int).Rem(leftVal, rightVal))
+	case "**":
+		if rightVal.Sign() < 0 {
+			base, _ := new(big.Float).SetInt(leftVal).Float64()
+			exp, _ := new(big.Float).SetInt(rightVal).Float64()
+			return &object.Float{Value: math.Pow(base, exp)}
+		}
+		// The result has about exponent × the base's bit length bits; bases
+		// 0, 1 and -1 keep their size whatever the exponent.
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 &&
+			(!rightVal.IsUint64() || rightVal.Uint64() > maxBigShift/uint64(leftVal.BitLen())) {
+			return newError("exponent too large: %s", rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Exp(leftVal, rightVal, nil))
+	case "&":
+		return normalizeBigInteger(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return normalizeBigInteger(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return normalizeBigInteger(new(big.Int).Xor(leftVal, rightVal))
+	case "&^":
+		return normalizeBigInteger(new(big.Int).AndNot(leftVal, rightVal))
+	case "<<":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if leftVal.Sign() == 0 {
+			return &object.Integer{Value: 0}
+		}
+		if !rightVal.IsUint64() || rightVal.Uint64() > maxBigShift {
+			return newError("shift count too large: %s", rightVal)
+		}
+		return normalizeBigInteger(new(big.Int).Lsh(leftVal, uint(rightVal.Uint64())))
+	case ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if !rightVal.IsUint64() || rightVal.Uint64() > math.MaxUint32 {
+			// Every bit has been shifted out; only the sign remains.
+			return &object.Integer{Value: int64(leftVal.Sign() >> 1)}
+		}
+		return normalizeBigInteger(new(big.Int).Rsh(leftVal, uint(rightVal.Uint64())))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			object.BIG_INTEGER_OBJ, operator, object.BIG_INTEGER_OBJ)
+	}
+}
+
+// normalizeBigInteger returns an object.Integer when value fits in 64 bits,
+// so that promoted results shrink back once they are small again.
+func normalizeBigInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIG_INTEGER_OBJ
+}
+
+// toBigInt converts an Integer or BigInteger object to a *big.Int.
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	default:
+		return new(big.Int)
+	}
+}
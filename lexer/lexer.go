@@ -1,34 +1,94 @@
 package lexer
 
-import "bangu/token"
+import (
+	"bangu/token"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 type Lexer struct {
+	filename     string
 	input        string
-	position     int  // current position in input (points to current char)
-	readPosition int  // current reading position (after current char)
-	ch           byte // current char under examination
+	position     int  // current byte position in input (points to current char)
+	readPosition int  // current byte reading position (after current char)
+	ch           rune // current char under examination
+	line         int  // line of the current char
+	column       int  // column of the current char, counted in runes
+
+	// templates has an entry for each ${ interpolation being lexed, counting
+	// the braces opened inside it, so that the } ending it can be told
+	// apart from the } ending a block or hash.
+	templates []int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// NewFile creates a Lexer whose token positions carry the given file name.
+// A "#!" line at the very start of the input is skipped, so that scripts can
+// be run directly on Unix-like systems.
+func NewFile(filename, input string) *Lexer {
+	l := &Lexer{filename: filename, input: input, line: 1}
 	l.readChar()
+	if l.ch == '#' && l.PeekChar() == '!' {
+		l.skipLineComment()
+	}
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	width := 0
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // ASCII code for 'NUL', signals end of file
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += max(width, 1)
+	l.column++
+}
+
+// pos returns the position of the current char.
+func (l *Lexer) pos() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
 }
 
+// NextToken returns the next token in the input together with the span of
+// source it was read from and the comments preceding it.
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+	comments, ok := l.skipWhitespaceAndComments()
+	if !ok {
+		// The last comment ran to the end of the input.
+		unterminated := comments[len(comments)-1]
+		return token.Token{
+			Type:    token.ILLEGAL,
+			Literal: "unterminated comment",
+			Span:    unterminated.Span,
+			Leading: comments[:len(comments)-1],
+		}
+	}
+
+	start := l.pos()
+	tok := l.scanToken()
+	tok.Span = token.Span{Start: start, End: l.pos()}
+	tok.Leading = comments
+	return tok
+}
 
-	l.skipWhitespace()
+func (l *Lexer) scanToken() token.Token {
+	var tok token.Token
 
 	switch l.ch {
 	case '=':
@@ -40,9 +100,9 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.pairToken('=', token.PLUS_ASSIGN, token.PLUS)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.pairToken('=', token.MINUS_ASSIGN, token.MINUS)
 	case '!':
 		if l.PeekChar() == '=' {
 			ch := l.ch
@@ -52,31 +112,72 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.PeekChar() == '*' {
+			l.readChar()
+			tok = token.Token{Type: token.POWER, Literal: token.POWER}
+		} else {
+			tok = l.pairToken('=', token.ASTERISK_ASSIGN, token.ASTERISK)
+		}
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
+	case '&':
+		if l.PeekChar() == '^' {
+			tok = l.pairToken('^', token.AND_NOT, token.AMPERSAND)
+		} else {
+			tok = l.pairToken('&', token.AND, token.AMPERSAND)
+		}
+	case '|':
+		tok = l.pairToken('|', token.OR, token.PIPE)
+	case '^':
+		tok = newToken(token.CARET, l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		tok = l.pairToken('=', token.SLASH_ASSIGN, token.SLASH)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.PeekChar() == '<' {
+			tok = l.pairToken('<', token.SHL, token.LT)
+		} else {
+			tok = l.pairToken('=', token.LT_EQ, token.LT)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.PeekChar() == '>' {
+			tok = l.pairToken('>', token.SHR, token.GT)
+		} else {
+			tok = l.pairToken('=', token.GT_EQ, token.GT)
+		}
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
 		tok = newToken(token.RPAREN, l.ch)
 	case '{':
+		if n := len(l.templates); n > 0 {
+			l.templates[n-1]++
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		if n := len(l.templates); n > 0 {
+			if l.templates[n-1] == 0 {
+				// The end of an interpolation: the string continues.
+				l.templates = l.templates[:n-1]
+				return l.readStringToken(token.TEMPLATE_TAIL, token.TEMPLATE_MIDDLE)
+			}
+			l.templates[n-1]--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case '"':
+		return l.readStringToken(token.STRING, token.TEMPLATE_HEAD)
+	case '`':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
-		if tok.Literal == "" {
+		str, ok := l.readRawString()
+		tok.Literal = str
+		if !ok {
 			tok.Type = token.ILLEGAL
-			tok.Literal = "unterminated string"
+			tok.Literal = "unterminated raw string"
 		}
 		return tok
 
@@ -87,6 +188,14 @@ func (l *Lexer) NextToken() token.Token {
 
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if strings.HasPrefix(l.input[l.position:], token.ELLIPSIS) {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: token.ELLIPSIS}
+		} else {
+			tok = illegalCharacter(l.ch)
+		}
 
 	case 0:
 		tok.Literal = ""
@@ -97,12 +206,13 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
-		} else if isDigital(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
+		} else if isDecimalDigit(l.ch) {
+			tok.Literal, tok.Type = l.readNumber()
 			return tok
+		} else if l.ch == utf8.RuneError && l.width() == 1 {
+			tok = token.Token{Type: token.ILLEGAL, Literal: "invalid UTF-8 encoding"}
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = illegalCharacter(l.ch)
 		}
 	}
 
@@ -110,32 +220,98 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
+// illegalCharacter returns an ILLEGAL token whose literal describes ch.
+func illegalCharacter(ch rune) token.Token {
+	return token.Token{Type: token.ILLEGAL, Literal: fmt.Sprintf("illegal character %q", ch)}
+}
+
+// pairToken returns a two-character token of type pair when the current char
+// is followed by next, such as "+=", and a one-character token of type
+// single otherwise.
+func (l *Lexer) pairToken(next rune, pair, single token.TokenType) token.Token {
+	if l.PeekChar() != next {
+		return newToken(single, l.ch)
+	}
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: pair, Literal: string(ch) + string(l.ch)}
+}
+
+// readIdentifier reads an identifier. Identifiers start with a letter or an
+// underscore, and may go on with letters, digits and combining marks, so
+// that words such as নাম, whose vowel signs are marks, are one identifier.
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) || unicode.In(l.ch, unicode.Mn, unicode.Mc) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func isLetter(ch byte) bool {
-	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch == '_'
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or a floating-point literal such as 3.14,
+// 1e9, 0xff, 0o17, 0b1010, 1_000_000 or ১২৩, and returns it along with its
+// token type. Letters and digits that run on from the literal are read as part of
+// it, so that a malformed literal such as 0xfg or 12abc is reported by the
+// parser as a whole instead of being split into several tokens.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
-	for isDigital(l.ch) || l.ch == '.' {
+	tokType := token.TokenType(token.INT)
+
+	if l.ch == '0' && strings.ContainsRune("xXoObB", l.PeekChar()) {
+		l.readChar() // Consume the '0'
+		l.readChar() // Consume the base prefix
+		l.readAlphanumerics()
+		return l.input[position:l.position], tokType
+	}
+
+	l.readDigits()
+	if l.ch == '.' && isDecimalDigit(l.PeekChar()) {
+		tokType = token.FLOAT
+		l.readChar() // Consume the '.'
+		l.readDigits()
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		tokType = token.FLOAT
+		l.readChar() // Consume the 'e'
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		l.readDigits()
+	}
+	l.readAlphanumerics()
+
+	return l.input[position:l.position], tokType
+}
+
+// readDigits reads decimal digits and the underscores separating them.
+func (l *Lexer) readDigits() {
+	for isDecimalDigit(l.ch) || l.ch == '_' {
 		l.readChar()
 	}
-	return l.input[position:l.position]
 }
 
-func isDigital(ch byte) bool {
-	return '0' <= ch && ch <= '9' || ch == '.'
+func (l *Lexer) readAlphanumerics() {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
+		l.readChar()
+	}
+}
+
+func isDigital(ch rune) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// isDecimalDigit reports whether ch is a digit that may be used in a decimal
+// number literal: an ASCII digit or a Bengali digit from ০ to ৯.
+func isDecimalDigit(ch rune) bool {
+	return isDigital(ch) || ('০' <= ch && ch <= '৯')
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -144,30 +320,215 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func (l *Lexer) PeekChar() byte {
+// skipWhitespaceAndComments skips whitespace, `//` line comments and `/* */`
+// block comments, returning the comments it read. It reports false if the
+// last comment is a block comment that is never closed.
+func (l *Lexer) skipWhitespaceAndComments() ([]token.Comment, bool) {
+	var comments []token.Comment
+
+	for {
+		l.skipWhitespace()
+		if l.ch != '/' || (l.PeekChar() != '/' && l.PeekChar() != '*') {
+			return comments, true
+		}
+
+		start := l.pos()
+		terminated := true
+		if l.PeekChar() == '/' {
+			l.skipLineComment()
+		} else {
+			terminated = l.skipBlockComment()
+		}
+
+		end := l.pos()
+		comments = append(comments, token.Comment{
+			Text: l.input[start.Offset:end.Offset],
+			Span: token.Span{Start: start, End: end},
+		})
+		if !terminated {
+			return comments, false
+		}
+	}
+}
+
+func (l *Lexer) skipLineComment() {
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+}
+
+// skipBlockComment skips a block comment, including any nested block
+// comments, and reports whether it was closed.
+func (l *Lexer) skipBlockComment() bool {
+	depth := 0
+	for l.ch != 0 {
+		switch {
+		case l.ch == '/' && l.PeekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.PeekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return true
+			}
+		}
+		l.readChar()
+	}
+	return false
+}
+
+func (l *Lexer) PeekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0 // ASCII code for 'NUL', signals end of file
 	} else {
-		return l.input[l.readPosition]
+		ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+		return ch
+	}
+}
+
+// width returns the number of bytes the current char takes up in the input.
+func (l *Lexer) width() int {
+	return l.readPosition - l.position
+}
+
+// readStringToken reads the text of a string from the current char, which
+// is its opening quote or the } ending an interpolation. The token has type
+// closed if the text runs to the closing quote, and type open if it stops at
+// an interpolation.
+func (l *Lexer) readStringToken(closed, open token.TokenType) token.Token {
+	str, interpolation, problem := l.readString()
+	switch {
+	case problem != "":
+		return token.Token{Type: token.ILLEGAL, Literal: problem}
+	case interpolation:
+		return token.Token{Type: open, Literal: str}
+	default:
+		return token.Token{Type: closed, Literal: str}
+	}
+}
+
+// readString reads the text of a double-quoted string up to its closing
+// quote or to the ${ starting an interpolation, which it reports, and
+// decodes its escape sequences. If the string is malformed it returns a
+// description of the first problem; the rest of the string is still read,
+// so lexing resumes after it.
+func (l *Lexer) readString() (string, bool, string) {
+	var out strings.Builder
+	problem := ""
+
+	for {
+		l.readChar()
+		switch l.ch {
+		case 0:
+			return "", false, "unterminated string"
+		case '"':
+			l.readChar() // Consume the closing quote
+			return out.String(), false, problem
+		case '$':
+			if l.PeekChar() != '{' {
+				out.WriteRune(l.ch)
+				continue
+			}
+			l.readChar()
+			l.readChar() // Consume the ${
+			l.templates = append(l.templates, 0)
+			return out.String(), true, problem
+		case '\\':
+			start := l.position
+			l.readChar() // Move to the escaped char
+			if l.ch == 0 {
+				return "", false, "unterminated string"
+			}
+			decoded, ok := l.readEscape()
+			if !ok && problem == "" {
+				// The sequence ends with the current char, which may take
+				// several bytes, as in "\৯".
+				end := min(l.readPosition, len(l.input))
+				problem = fmt.Sprintf("invalid escape sequence %s", l.input[start:end])
+			}
+			out.WriteString(decoded)
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// escapes maps the char after a backslash to the text it stands for.
+var escapes = map[rune]string{
+	'n':  "\n",
+	't':  "\t",
+	'r':  "\r",
+	'0':  "\x00",
+	'\\': "\\",
+	'"':  "\"",
+	'$':  "$",
+}
+
+// readEscape decodes the escape sequence whose first char, after the
+// backslash, is the current char. It leaves the lexer on the last char of
+// the sequence and reports whether the sequence was valid.
+func (l *Lexer) readEscape() (string, bool) {
+	if decoded, ok := escapes[l.ch]; ok {
+		return decoded, true
+	}
+	if l.ch != 'u' {
+		return "", false
+	}
+
+	// A Unicode escape such as \u{1F600}: one to six hex digits in braces.
+	if l.PeekChar() != '{' {
+		return "", false
+	}
+	l.readChar() // Move to the '{'
+	var code rune
+	digits := 0
+	for isHexDigit(l.PeekChar()) {
+		l.readChar()
+		code = code<<4 | hexValue(l.ch)
+		digits++
+	}
+	if l.PeekChar() != '}' || digits == 0 || digits > 6 {
+		return "", false
+	}
+	l.readChar() // Move to the '}'
+	if !utf8.ValidRune(code) {
+		return "", false
+	}
+	return string(code), true
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigital(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
+}
+
+func hexValue(ch rune) rune {
+	switch {
+	case isDigital(ch):
+		return ch - '0'
+	case 'a' <= ch && ch <= 'f':
+		return ch - 'a' + 10
+	default:
+		return ch - 'A' + 10
 	}
 }
 
-func (l *Lexer) readString() string {
+// readRawString reads a backtick-quoted string, which may span lines and
+// has no escape sequences, and reports whether its closing backtick was
+// found. Carriage returns are dropped, as in Go, so a file saved with CRLF
+// line endings gives the same string.
+func (l *Lexer) readRawString() (string, bool) {
 	position := l.position + 1
 	for {
 		l.readChar()
 		if l.ch == 0 {
-			return ""
+			return "", false
 		}
-		if l.ch == '\\' {
-			l.readChar()
-			continue
-		}
-		if l.ch == '"' {
+		if l.ch == '`' {
 			str := l.input[position:l.position]
-			l.readChar() // Consume the closing quote
-			return str
+			l.readChar() // Consume the closing backtick
+			return strings.ReplaceAll(str, "\r", ""), true
 		}
 	}
-	return l.input[position:l.position]
 }
@@ -13,7 +13,7 @@ func TestNextToken(t *testing.T) {
     };
 
     let result = add(five, ten);
-	!-/*5
+	!-/ *5
 	5 < 10 > 5;
 
 
@@ -29,6 +29,10 @@ func TestNextToken(t *testing.T) {
 	"foo bar"
 	[1, 2];
 	{"foo": "bar"};
+	while for in break continue
+	+= -= *= /=
+	<= >= && || % **
+	& | ^ ~ &^ << >>
 
     `
 
@@ -135,7 +139,175 @@ func TestNextToken(t *testing.T) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+
+		{token.WHILE, "while"},
+		{token.FOR, "for"},
+		{token.IN, "in"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+
+		{token.PLUS_ASSIGN, "+="},
+		{token.MINUS_ASSIGN, "-="},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.SLASH_ASSIGN, "/="},
+
+		{token.LT_EQ, "<="},
+		{token.GT_EQ, ">="},
+		{token.AND, "&&"},
+		{token.OR, "||"},
+		{token.PERCENT, "%"},
+		{token.POWER, "**"},
+
+		{token.AMPERSAND, "&"},
+		{token.PIPE, "|"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.AND_NOT, "&^"},
+		{token.SHL, "<<"},
+		{token.SHR, ">>"},
+
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 10;\n  add(x, \"hi\")"
+
+	pos := func(offset, line, column int) token.Position {
+		return token.Position{Filename: "test.bangu", Offset: offset, Line: line, Column: column}
+	}
+
+	tests := []struct {
+		expectedType  token.TokenType
+		expectedStart token.Position
+		expectedEnd   token.Position
+	}{
+		{token.LET, pos(0, 1, 1), pos(3, 1, 4)},
+		{token.IDENT, pos(4, 1, 5), pos(5, 1, 6)},
+		{token.ASSIGN, pos(6, 1, 7), pos(7, 1, 8)},
+		{token.INT, pos(8, 1, 9), pos(10, 1, 11)},
+		{token.SEMICOLON, pos(10, 1, 11), pos(11, 1, 12)},
+		{token.IDENT, pos(14, 2, 3), pos(17, 2, 6)},
+		{token.LPAREN, pos(17, 2, 6), pos(18, 2, 7)},
+		{token.IDENT, pos(18, 2, 7), pos(19, 2, 8)},
+		{token.COMMA, pos(19, 2, 8), pos(20, 2, 9)},
+		{token.STRING, pos(21, 2, 10), pos(25, 2, 14)},
+		{token.RPAREN, pos(25, 2, 14), pos(26, 2, 15)},
+		{token.EOF, pos(26, 2, 15), pos(26, 2, 15)},
+	}
+
+	l := NewFile("test.bangu", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Span.Start != tt.expectedStart {
+			t.Errorf("tests[%d] - start wrong. expected=%+v, got=%+v",
+				i, tt.expectedStart, tok.Span.Start)
+		}
+
+		if tok.Span.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong. expected=%+v, got=%+v",
+				i, tt.expectedEnd, tok.Span.End)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 10 / 2; // trailing
+/* block /* nested */ still comment */ x
+/* unterminated`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLeading []string
+	}{
+		{token.LET, "let", []string{"// leading comment"}},
+		{token.IDENT, "x", nil},
+		{token.ASSIGN, "=", nil},
+		{token.INT, "10", nil},
+		{token.SLASH, "/", nil},
+		{token.INT, "2", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "x", []string{"// trailing", "/* block /* nested */ still comment */"}},
+		{token.ILLEGAL, "unterminated comment", nil},
+		{token.EOF, "", nil},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
 
+		if len(tok.Leading) != len(tt.expectedLeading) {
+			t.Fatalf("tests[%d] - wrong number of comments. expected=%d, got=%d",
+				i, len(tt.expectedLeading), len(tok.Leading))
+		}
+		for j, text := range tt.expectedLeading {
+			if tok.Leading[j].Text != text {
+				t.Errorf("tests[%d] - comment[%d] wrong. expected=%q, got=%q",
+					i, j, text, tok.Leading[j].Text)
+			}
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `3 3.14 1e9 2.5E-3 6e+2 7.foo 0xFF 0o17 0b1010 1_000_000 1_000.5 0xfg 12abc`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "3"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "1e9"},
+		{token.FLOAT, "2.5E-3"},
+		{token.FLOAT, "6e+2"},
+		{token.INT, "7"},
+		{token.ILLEGAL, "illegal character '.'"},
+		{token.IDENT, "foo"},
+		{token.INT, "0xFF"},
+		{token.INT, "0o17"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.FLOAT, "1_000.5"},
+		{token.INT, "0xfg"},
+		{token.INT, "12abc"},
 		{token.EOF, ""},
 	}
 
@@ -155,3 +327,254 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStrings(t *testing.T) {
+	input := "\"a\\nb\" \"tab\\there\" \"say \\\"hi\\\"\" \"back\\\\slash\" " +
+		"\"\\u{48}\\u{1F600}\" \"bad \\q escape\" \"bad \\u{110000}\" \"a\\৯b\" 1 " +
+		"`raw \\n ${x}\r\nline two` `unterminated"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "a\nb"},
+		{token.STRING, "tab\there"},
+		{token.STRING, `say "hi"`},
+		{token.STRING, `back\slash`},
+		{token.STRING, "H\U0001F600"},
+		{token.ILLEGAL, `invalid escape sequence \q`},
+		{token.ILLEGAL, `invalid escape sequence \u{110000}`},
+		{token.ILLEGAL, `invalid escape sequence \৯`},
+		{token.INT, "1"},
+		{token.STRING, "raw \\n ${x}\nline two"},
+		{token.ILLEGAL, "unterminated raw string"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	input := `"Hello ${name}, ${ {"a": "${x}"}["a"] }!" "\${not} $5" "${}"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE_HEAD, "Hello "},
+		{token.IDENT, "name"},
+		{token.TEMPLATE_MIDDLE, ", "},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.TEMPLATE_HEAD, ""},
+		{token.IDENT, "x"},
+		{token.TEMPLATE_TAIL, ""},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "a"},
+		{token.RBRACKET, "]"},
+		{token.TEMPLATE_TAIL, "!"},
+		{token.STRING, "${not} $5"},
+		{token.TEMPLATE_HEAD, ""},
+		{token.TEMPLATE_TAIL, ""},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	input := "let নাম = \"বাংলা\"; x1 + _y২ café §\n\xff নাম"
+
+	pos := func(offset, line, column int) token.Position {
+		return token.Position{Offset: offset, Line: line, Column: column}
+	}
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedStart   token.Position
+	}{
+		{token.LET, "let", pos(0, 1, 1)},
+		{token.IDENT, "নাম", pos(4, 1, 5)},
+		{token.ASSIGN, "=", pos(14, 1, 9)},
+		{token.STRING, "বাংলা", pos(16, 1, 11)},
+		{token.SEMICOLON, ";", pos(33, 1, 18)},
+		{token.IDENT, "x1", pos(35, 1, 20)},
+		{token.PLUS, "+", pos(38, 1, 23)},
+		{token.IDENT, "_y২", pos(40, 1, 25)},
+		{token.IDENT, "café", pos(46, 1, 29)},
+		{token.ILLEGAL, "illegal character '§'", pos(52, 1, 34)},
+		{token.ILLEGAL, "invalid UTF-8 encoding", pos(55, 2, 1)},
+		{token.IDENT, "নাম", pos(57, 2, 3)},
+		{token.EOF, "", pos(66, 2, 6)},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Span.Start != tt.expectedStart {
+			t.Errorf("tests[%d] - start wrong. expected=%+v, got=%+v",
+				i, tt.expectedStart, tok.Span.Start)
+		}
+	}
+}
+
+func TestBanglaKeywordsAndDigits(t *testing.T) {
+	token.UseVocabularies(token.English, token.Bangla)
+	defer token.UseVocabularies(token.English)
+
+	input := "ধরি বয়স = ২০; যদি নাহলে কাজ ফেরত সত্য মিথ্যা let ১.৫ ১_০০০ ০x১"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "ধরি"},
+		{token.IDENT, "বয়স"},
+		{token.ASSIGN, "="},
+		{token.INT, "২০"},
+		{token.SEMICOLON, ";"},
+		{token.IF, "যদি"},
+		{token.ELSE, "নাহলে"},
+		{token.FUNCTION, "কাজ"},
+		{token.RETURN, "ফেরত"},
+		{token.TRUE, "সত্য"},
+		{token.FALSE, "মিথ্যা"},
+		{token.LET, "let"},
+		{token.FLOAT, "১.৫"},
+		{token.INT, "১_০০০"},
+		{token.INT, "০x১"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUseVocabularies(t *testing.T) {
+	defer token.UseVocabularies(token.English)
+
+	if tok := New("ফেরত").NextToken(); tok.Type != token.IDENT {
+		t.Errorf("Bangla keyword recognized by default. got=%q", tok.Type)
+	}
+
+	token.UseVocabularies(token.English)
+	if tok := New("ধরি").NextToken(); tok.Type != token.IDENT {
+		t.Errorf("Bangla keyword recognized in English mode. got=%q", tok.Type)
+	}
+
+	token.UseVocabularies(token.Bangla)
+	if tok := New("let").NextToken(); tok.Type != token.IDENT {
+		t.Errorf("English keyword recognized in Bangla mode. got=%q", tok.Type)
+	}
+	if tok := New("ধরি").NextToken(); tok.Type != token.LET {
+		t.Errorf("Bangla keyword not recognized in Bangla mode. got=%q", tok.Type)
+	}
+}
+
+func TestParseVocabularies(t *testing.T) {
+	tests := []struct {
+		list     string
+		expected []token.Vocabulary
+		err      string
+	}{
+		{"en", []token.Vocabulary{token.English}, ""},
+		{"bn", []token.Vocabulary{token.Bangla}, ""},
+		{"en,bn", []token.Vocabulary{token.English, token.Bangla}, ""},
+		{"en,fr", nil, `unknown keyword language "fr" (want en, bn or en,bn)`},
+		{"", nil, `unknown keyword language "" (want en, bn or en,bn)`},
+	}
+
+	for _, tt := range tests {
+		vocabularies, err := token.ParseVocabularies(tt.list)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("ParseVocabularies(%q) error = %v, want %q", tt.list, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || len(vocabularies) != len(tt.expected) {
+			t.Errorf("ParseVocabularies(%q) = %d vocabularies, %v; want %d", tt.list, len(vocabularies), err, len(tt.expected))
+			continue
+		}
+		for i, v := range vocabularies {
+			if v[token.LET] != tt.expected[i][token.LET] {
+				t.Errorf("ParseVocabularies(%q)[%d] spells let as %q, want %q", tt.list, i, v[token.LET], tt.expected[i][token.LET])
+			}
+		}
+	}
+}
+
+func TestShebang(t *testing.T) {
+	l := NewFile("script.bangu", "#!/usr/bin/env bangu\nlet")
+
+	tok := l.NextToken()
+	if tok.Type != token.LET {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.LET, tok.Type)
+	}
+	if tok.Span.Start.String() != "script.bangu:2:1" {
+		t.Errorf("position wrong. expected=script.bangu:2:1, got=%s", tok.Span.Start)
+	}
+
+	// Only the first line can be a shebang.
+	l = New("1\n#!")
+	l.NextToken()
+	if tok := l.NextToken(); tok.Type != token.ILLEGAL {
+		t.Errorf("tokentype wrong. expected=%q, got=%q", token.ILLEGAL, tok.Type)
+	}
+}
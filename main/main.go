@@ -1,19 +1,180 @@
 package main
 
 import (
+	"bangu/evaluator"
+	"bangu/lexer"
+	"bangu/object"
+	"bangu/parser"
 	"bangu/repl"
+	"bangu/token"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 )
 
+// Exit statuses of the bangu command.
+const (
+	exitOK           = 0
+	exitRuntimeError = 1 // the program failed while running
+	exitParseError   = 2 // the program could not be read or parsed, or the command line was wrong
+)
+
+const usage = `Usage:
+  bangu [-journal FILE]          start the interactive prompt
+  bangu run FILE [ARG...]        run the script FILE ("-" reads it from stdin)
+  bangu FILE [ARG...]            the same, for scripts starting with #!/usr/bin/env bangu
+  bangu -e PROGRAM [ARG...]      run PROGRAM and print its value
+  bangu < FILE                   run the script read from stdin
+
+The script's arguments are available to it as the array args.
+
+Flags:
+`
+
 func main() {
-	user, err := user.Current()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the bangu command with the given command-line arguments and
+// returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bangu", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		io.WriteString(stderr, usage)
+		flags.PrintDefaults()
+	}
+	expr := flags.String("e", "", "run `program` given on the command line")
+	overflow := flags.String("overflow", evaluator.OverflowWrap.String(),
+		"what integer overflow does: wrap, error or promote")
+	lang := flags.String("lang", "en",
+		"the `languages` whose keywords are recognized: en, bn or en,bn")
+	journal := flags.String("journal", "",
+		"record the interactive session in `file`, to restore it after a crash")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitParseError
+	}
+	args = flags.Args()
+
+	// Flags may also follow the run subcommand, as in bangu run -overflow error x.bangu.
+	isRun := *expr == "" && len(args) > 0 && args[0] == "run"
+	if isRun {
+		if err := flags.Parse(args[1:]); err != nil {
+			return exitParseError
+		}
+		args = flags.Args()
+	}
+
+	policy, err := evaluator.ParseOverflowPolicy(*overflow)
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(stderr, "bangu: %s\n", err)
+		return exitParseError
+	}
+	evaluator.IntegerOverflow = policy
+
+	vocabularies, err := token.ParseVocabularies(*lang)
+	if err != nil {
+		fmt.Fprintf(stderr, "bangu: %s\n", err)
+		return exitParseError
+	}
+	token.UseVocabularies(vocabularies...)
+	evaluator.Stdout = stdout
+
+	switch {
+	case *expr != "":
+		return execute("-e", *expr, args, stdout, stderr, true)
+	case len(args) > 0:
+		name, source, err := readScript(args[0], stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "bangu: %s\n", err)
+			return exitParseError
+		}
+		return execute(name, source, args[1:], stdout, stderr, false)
+	case isRun:
+		fmt.Fprintln(stderr, "bangu: run needs a script file")
+		flags.Usage()
+		return exitParseError
+	case !isTerminal(stdin):
+		_, source, err := readScript("-", stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "bangu: %s\n", err)
+			return exitParseError
+		}
+		return execute("<stdin>", source, nil, stdout, stderr, false)
+	default:
+		greet(stdout)
+		summary := repl.StartWithOptions(stdin, stdout, repl.Options{Journal: *journal, Err: stderr})
+		if summary.RuntimeErrors > 0 {
+			return exitRuntimeError
+		}
+		return exitOK
+	}
+}
+
+// readScript reads the script at path, or stdin if path is "-", and returns
+// the name to report positions with along with its source.
+func readScript(path string, stdin io.Reader) (string, string, error) {
+	if path == "-" {
+		source, err := io.ReadAll(stdin)
+		return "<stdin>", string(source), err
+	}
+	source, err := os.ReadFile(path)
+	return path, string(source), err
+}
+
+// execute parses and evaluates a program, binding args to the array of its
+// arguments, and returns the exit status. When printResult is set the
+// program's value is printed, unless it is null.
+func execute(name, source string, args []string, stdout, stderr io.Writer, printResult bool) int {
+	l := lexer.NewFile(name, source)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		repl.PrintDiagnostics(stderr, source, p.Diagnostics())
+		return exitParseError
+	}
+
+	elements := make([]object.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &object.String{Value: arg}
+	}
+	env := object.NewEnvironment()
+	env.Set("args", &object.Array{Elements: elements})
+
+	evaluated := evaluator.Eval(program, env)
+	if errObj, ok := evaluated.(*object.Error); ok {
+		repl.PrintRuntimeError(stderr, source, errObj)
+		return exitRuntimeError
+	}
+	if printResult && evaluated != nil && evaluated != evaluator.NULL {
+		fmt.Fprintln(stdout, evaluated.Inspect())
+	}
+	return exitOK
+}
+
+// isTerminal reports whether r is a terminal rather than a file or pipe.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func greet(out io.Writer) {
+	name := "there"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
 	}
-	fmt.Printf("Hello  %s! This is the Bangu programming language!\n",
-		user.Username)
-	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
+	fmt.Fprintf(out, "Hello  %s! This is the Bangu programming language!\n", name)
+	fmt.Fprintf(out, "Feel free to type in commands\n")
 }
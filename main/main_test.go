@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bangu/evaluator"
+	"bangu/token"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	defer func(policy evaluator.OverflowPolicy) { evaluator.IntegerOverflow = policy }(evaluator.IntegerOverflow)
+	defer func() { evaluator.Stdout = os.Stdout }()
+	defer token.UseVocabularies(token.English)
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hello.bangu")
+	os.WriteFile(script, []byte("#!/usr/bin/env bangu\nputs(\"Hello ${first(args)}\", len(args));\n"), 0o644)
+	broken := filepath.Join(dir, "broken.bangu")
+	os.WriteFile(broken, []byte("let x = 1;\nlet y = x +;\n"), 0o644)
+
+	tests := []struct {
+		args           []string
+		stdin          string
+		expectedStatus int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{[]string{"run", script, "Ada", "Lovelace"}, "", exitOK, "Hello Ada\n2\n", ""},
+		{[]string{script, "Zed"}, "", exitOK, "Hello Zed\n1\n", ""},
+		{[]string{"run", "-", "x"}, "puts(args)", exitOK, "[x]\n", ""},
+		{nil, "puts(1 + 2)", exitOK, "3\n", ""},
+		{[]string{"-e", "1 + 2"}, "", exitOK, "3\n", ""},
+		{[]string{"-e", "puts(\"hi\")"}, "", exitOK, "hi\n", ""},
+		{[]string{"-e", "2 ** 64"}, "", exitOK, "0\n", ""},
+		{[]string{"-overflow", "promote", "-e", "2 ** 64"}, "", exitOK, "18446744073709551616\n", ""},
+		{[]string{"run", "-overflow", "error", "-", "x"}, "2 ** 64", exitRuntimeError, "", "<stdin>:1:1: runtime error: integer overflow: 2 ** 64\n\t2 ** 64\n\t^^^^^^^\n"},
+		{[]string{broken}, "", exitParseError, "", broken + ":2:12: error: no prefix parse function for ; found\n"},
+		{[]string{"-e", "1 / 0"}, "", exitRuntimeError, "", "-e:1:1: runtime error: division by zero\n\t1 / 0\n\t^^^^^\n"},
+		{[]string{"-e", "let f = fn() { 1 / 0 }; f()"}, "", exitRuntimeError, "",
+			"-e:1:16: runtime error: division by zero\n\tlet f = fn() { 1 / 0 }; f()\n\t               ^^^^^\n\tin f, called at -e:1:25\n"},
+		{[]string{"run", filepath.Join(dir, "missing.bangu")}, "", exitParseError, "", "bangu: open "},
+		{[]string{dir}, "", exitParseError, "", "bangu: read "},
+		{[]string{"-overflow", "sideways", "-e", "1"}, "", exitParseError, "", "bangu: unknown overflow policy"},
+		{[]string{"-e", "let ফেরত = 2; ফেরত * 3"}, "", exitOK, "6\n", ""},
+		{[]string{"-lang", "bn", "-e", "ধরি ক = ২; ক * 3"}, "", exitOK, "6\n", ""},
+		{[]string{"run", "-lang", "en,bn", "-"}, "let x = যদি (সত্য) { 1 } else { 2 }; puts(x)", exitOK, "1\n", ""},
+		{[]string{"-lang", "fr", "-e", "1"}, "", exitParseError, "", "bangu: unknown keyword language \"fr\""},
+		{[]string{"-nope"}, "", exitParseError, "", "flag provided but not defined: -nope\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		status := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+		if status != tt.expectedStatus {
+			t.Errorf("%q: exit status wrong. want=%d, got=%d (stderr %q)", tt.args, tt.expectedStatus, status, stderr.String())
+		}
+		if stdout.String() != tt.expectedStdout {
+			t.Errorf("%q: stdout wrong. want=%q, got=%q", tt.args, tt.expectedStdout, stdout.String())
+		}
+		if !strings.HasPrefix(stderr.String(), tt.expectedStderr) {
+			t.Errorf("%q: stderr wrong. want prefix %q, got=%q", tt.args, tt.expectedStderr, stderr.String())
+		}
+	}
+}
@@ -2,9 +2,13 @@ package object
 
 import (
 	"bangu/ast"
+	"bangu/token"
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +27,46 @@ func (i *Integer) Inspect() string {
 	return fmt.Sprintf("%d", i.Value)
 }
 
+// BigInteger is an arbitrary-precision integer. It only appears when integer
+// arithmetic overflows under the promote overflow policy.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Type() ObjectType { return BIG_INTEGER_OBJ }
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect formats the float in plain decimal notation, switching to exponent
+// notation for very large or very small magnitudes. Whole numbers keep a
+// trailing ".0" so they cannot be mistaken for integers.
+func (f *Float) Inspect() string {
+	switch {
+	case math.IsInf(f.Value, 1):
+		return "Inf"
+	case math.IsInf(f.Value, -1):
+		return "-Inf"
+	case math.IsNaN(f.Value):
+		return "NaN"
+	}
+
+	var s string
+	if abs := math.Abs(f.Value); abs == 0 || (abs >= 1e-4 && abs < 1e21) {
+		s = strconv.FormatFloat(f.Value, 'f', -1, 64)
+	} else {
+		s = strconv.FormatFloat(f.Value, 'g', -1, 64)
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
 func (h *Hash) Inspect() string {
 	var out bytes.Buffer
 
@@ -40,9 +84,14 @@ func (h *Hash) Inspect() string {
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIG_INTEGER_OBJ  = "BIG_INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
+	TAIL_CALL_OBJ    = "TAIL_CALL"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
@@ -85,8 +134,45 @@ func (rv *ReturnValue) Inspect() string {
 	return rv.Value.Inspect()
 }
 
+// Break and Continue travel up from a break or continue statement to the
+// innermost enclosing loop, the same way ReturnValue travels to its function.
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+// TailCall is a call whose value a function returns. Rather than making it
+// in a nested Go call, the function returns the TailCall for the caller to
+// make once the function's own evaluation has unwound, so that recursion in
+// tail position runs in constant stack space.
+type TailCall struct {
+	Function  Object
+	Arguments []Object
+	Call      *ast.CallExpression
+}
+
+func (tc *TailCall) Type() ObjectType { return TAIL_CALL_OBJ }
+func (tc *TailCall) Inspect() string  { return tc.Call.String() }
+
 type Error struct {
 	Message string
+	Span    token.Span // The source of the expression that failed, if known.
+	Stack   []Frame    // The function calls the error returned from, innermost first.
+}
+
+// Frame is a function call that was in progress when an error happened.
+type Frame struct {
+	Function string         // The name of the function called.
+	Call     token.Position // Where it was called.
+}
+
+func (f Frame) String() string {
+	return "in " + f.Function + ", called at " + f.Call.String()
 }
 
 func (e *Error) Type() ObjectType {
@@ -98,22 +184,20 @@ func (e *Error) Inspect() string {
 
 type Function struct {
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // Default values, parallel to Parameters; nil for parameters without one.
+	Rest       *ast.Identifier  // The variadic parameter, if any.
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Name       string // The name the function was first bound to with let, if any.
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
-
-	out.WriteString("fn")
+	out.WriteString(ast.Keyword(token.FUNCTION))
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(ast.ParametersString(f.Parameters, f.Defaults, f.Rest))
 	out.WriteString(") {\n")
 	out.WriteString(f.Body.String())
 	out.WriteString("\n}")
@@ -185,6 +269,29 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+func (bi *BigInteger) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write(bi.Value.Bytes())
+	if bi.Value.Sign() < 0 {
+		h.Write([]byte{'-'})
+	}
+	return HashKey{Type: bi.Type(), Value: h.Sum64()}
+}
+
+// HashKey returns the key of the equal integer for a float with an integral
+// value, since 1 == 1.0 must find the same hash entry as 1.
+func (f *Float) HashKey() HashKey {
+	value := f.Value
+	if value == math.Trunc(value) && !math.IsInf(value, 0) {
+		if value >= math.MinInt64 && value < math.MaxInt64 {
+			return (&Integer{Value: int64(value)}).HashKey()
+		}
+		i, _ := big.NewFloat(value).Int(nil)
+		return (&BigInteger{Value: i}).HashKey()
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(value)}
+}
+
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
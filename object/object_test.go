@@ -1,6 +1,10 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -17,3 +21,68 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{3.14, "3.14"},
+		{3, "3.0"},
+		{-0.5, "-0.5"},
+		{1e9, "1000000000.0"},
+		{1e21, "1e+21"},
+		{2.5e-7, "2.5e-07"},
+	}
+
+	for _, tt := range tests {
+		f := &Float{Value: tt.value}
+		if f.Inspect() != tt.expected {
+			t.Errorf("Inspect() wrong for %g. expected=%q, got=%q", tt.value, tt.expected, f.Inspect())
+		}
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("b", &Null{})
+	outer.Set("a", &Null{})
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("c", &Null{})
+	inner.Set("a", &Null{})
+
+	names := inner.Names()
+	expected := []string{"a", "b", "c"}
+	if len(names) != len(expected) {
+		t.Fatalf("wrong names. want=%v, got=%v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("wrong names. want=%v, got=%v", expected, names)
+		}
+	}
+}
+
+func TestNumberHashKey(t *testing.T) {
+	big70 := new(big.Int).Lsh(big.NewInt(1), 70)
+
+	tests := []struct {
+		a, b  Hashable
+		equal bool
+	}{
+		{&Integer{Value: 1}, &Float{Value: 1.0}, true},
+		{&Integer{Value: -3}, &Float{Value: -3.0}, true},
+		{&Integer{Value: 0}, &Float{Value: math.Copysign(0, -1)}, true},
+		{&BigInteger{Value: big70}, &Float{Value: math.Ldexp(1, 70)}, true},
+		{&Integer{Value: 1}, &Float{Value: 1.5}, false},
+		{&Float{Value: 1.5}, &Float{Value: 1.5}, true},
+		{&Float{Value: 1.5}, &Float{Value: 2.5}, false},
+	}
+
+	for _, tt := range tests {
+		if equal := tt.a.HashKey() == tt.b.HashKey(); equal != tt.equal {
+			t.Errorf("%s and %s: hash keys equal = %t, want %t",
+				tt.a.(Object).Inspect(), tt.b.(Object).Inspect(), equal, tt.equal)
+		}
+	}
+}
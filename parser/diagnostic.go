@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"bangu/token"
+	"fmt"
+)
+
+// Severity describes how serious a Diagnostic is.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Code identifies the kind of problem a Diagnostic reports. Codes are stable,
+// so tools can match on them instead of on the message text.
+type Code string
+
+const (
+	CodeUnexpectedToken  Code = "P001" // a specific token was expected
+	CodeNoPrefixParseFn  Code = "P002" // the token cannot start an expression
+	CodeInvalidInteger   Code = "P003" // an integer literal could not be parsed
+	CodeIllegalToken     Code = "P004" // the lexer could not make sense of the input
+	CodeInvalidFloat     Code = "P005" // a floating-point literal could not be parsed
+	CodeInvalidParameter Code = "P006" // a function parameter list is malformed
+	CodeInvalidTarget    Code = "P007" // the left side of an assignment cannot be assigned to
+)
+
+// Diagnostic is a problem found while parsing.
+type Diagnostic struct {
+	Severity Severity
+	Code     Code
+	Message  string
+	Span     token.Span        // The offending source range.
+	Expected []token.TokenType // The tokens that would have been accepted, if known.
+	Actual   token.TokenType   // The token that was found instead.
+	Hint     string            // An optional suggestion for fixing the problem.
+}
+
+// String formats the diagnostic as "line:column: severity: message".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Span.Start, d.Severity, d.Message)
+}
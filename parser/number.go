@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type numberBase struct {
+	name   string
+	digits string
+}
+
+var decimal = numberBase{"decimal", "0123456789"}
+
+// numberBases maps each base prefix to the digits allowed after it.
+var numberBases = map[string]numberBase{
+	"0x": {"hexadecimal", "0123456789abcdef"},
+	"0o": {"octal", "01234567"},
+	"0b": {"binary", "01"},
+}
+
+// normalizeDigits replaces the Bengali digits ০ to ৯ in a number literal
+// with the ASCII digits strconv understands.
+func normalizeDigits(lit string) string {
+	return strings.Map(func(ch rune) rune {
+		if '০' <= ch && ch <= '৯' {
+			return '0' + (ch - '০')
+		}
+		return ch
+	}, lit)
+}
+
+// parseInteger parses an integer literal whose digits have been normalized.
+// A literal without a base prefix is decimal even when it has leading
+// zeros, so 010 is 10 rather than the octal 8 it would be in Go.
+func parseInteger(lit string) (int64, error) {
+	if len(lit) >= 2 {
+		if _, ok := numberBases[strings.ToLower(lit[:2])]; ok {
+			return strconv.ParseInt(lit, 0, 64)
+		}
+	}
+
+	// strconv only accepts underscores in base 0, where a leading 0 would
+	// make the literal octal, so they are checked and dropped here.
+	for i := 0; i < len(lit); i++ {
+		if lit[i] == '_' && (i == 0 || i == len(lit)-1 || !isDecimalDigit(lit[i-1]) || !isDecimalDigit(lit[i+1])) {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: lit, Err: strconv.ErrSyntax}
+		}
+	}
+	return strconv.ParseInt(strings.ReplaceAll(lit, "_", ""), 10, 64)
+}
+
+func isDecimalDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// numberHint explains why a numeric literal could not be parsed.
+func numberHint(lit string, err error) string {
+	if errors.Is(err, strconv.ErrRange) {
+		return "the value does not fit in 64 bits"
+	}
+
+	base, body, prefixed := decimal, lit, false
+	if len(lit) >= 2 {
+		if b, ok := numberBases[strings.ToLower(lit[:2])]; ok {
+			base, body, prefixed = b, lit[2:], true
+		}
+	}
+	isDigit := func(ch rune) bool {
+		return strings.ContainsRune(base.digits, unicode.ToLower(ch))
+	}
+
+	for _, ch := range body {
+		if ch != '_' && !isDigit(ch) && (prefixed || !strings.ContainsRune(".eE+-", ch)) {
+			return fmt.Sprintf("%q is not a valid digit in a %s literal", ch, base.name)
+		}
+	}
+
+	if strings.Trim(body, "_") == "" {
+		return fmt.Sprintf("a %s literal needs at least one digit", base.name)
+	}
+
+	runes := []rune(body)
+	for i, ch := range runes {
+		if ch != '_' {
+			continue
+		}
+		// An underscore may follow the base prefix, as in 0x_ff, but must
+		// otherwise sit between two digits.
+		afterDigit := (i == 0 && prefixed) || (i > 0 && isDigit(runes[i-1]))
+		if !afterDigit || i+1 == len(runes) || !isDigit(runes[i+1]) {
+			return "underscores may only appear between digits, as in 1_000_000"
+		}
+	}
+
+	if strings.ContainsAny(body, "eE") {
+		return "an exponent needs digits, as in 1e9 or 2.5e-3"
+	}
+	return ""
+}
@@ -9,26 +9,47 @@ import (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:          ASSIGNMENT,
+	token.PLUS_ASSIGN:     ASSIGNMENT,
+	token.MINUS_ASSIGN:    ASSIGNMENT,
+	token.ASTERISK_ASSIGN: ASSIGNMENT,
+	token.SLASH_ASSIGN:    ASSIGNMENT,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.PIPE:            SUM,
+	token.CARET:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.AMPERSAND:       PRODUCT,
+	token.AND_NOT:         PRODUCT,
+	token.SHL:             PRODUCT,
+	token.SHR:             PRODUCT,
+	token.POWER:           POWER,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
 }
 
 const (
 	_ int = iota
 	LOWEST
+	ASSIGNMENT  // x = y
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      // ==
 	LESSGREATER // > or <
-	SUM         // +
-	PRODUCT     // *
-	PREFIX      // -X or !X
+	SUM         // + - | ^
+	PRODUCT     // * / % & &^ << >>
+	PREFIX      // -X, !X or ~X
+	POWER       // x ** y
 	CALL        // myFunction(X)
 	INDEX       // array[index]
 )
@@ -38,7 +59,10 @@ type Parser struct {
 	lexer     *lexer.Lexer
 	curToken  token.Token
 	peekToken token.Token
-	errors    []string
+	depth     int // number of '{' left open up to and including curToken
+
+	diagnostics []Diagnostic
+	panicking   bool // an error was reported and the parser has not yet recovered
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -52,8 +76,8 @@ type (
 // New creates a new Parser instance.
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		lexer:  l,
-		errors: []string{},
+		lexer:       l,
+		diagnostics: []Diagnostic{},
 	}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
@@ -61,8 +85,10 @@ func New(l *lexer.Lexer) *Parser {
 
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
@@ -71,6 +97,18 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.AND_NOT, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
@@ -78,9 +116,17 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE_HEAD, p.parseInterpolatedString)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
 
 	// Read two tokens, so curToken and peekToken are both set.
 	// This allows the parser to look ahead one token.
@@ -92,21 +138,72 @@ func New(l *lexer.Lexer) *Parser {
 	return p
 }
 
+// Diagnostics returns the problems encountered while parsing.
+func (p *Parser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// Errors returns the messages of the parsing errors encountered. It is kept
+// for callers that predate Diagnostics.
 func (p *Parser) Errors() []string {
-	// Returns the slice of parsing errors encountered.
-	return p.errors
+	errors := []string{}
+	for _, d := range p.diagnostics {
+		if d.Severity == SeverityError {
+			errors = append(errors, d.Message)
+		}
+	}
+	return errors
+}
+
+// report records a diagnostic. Once an error has been reported, further
+// errors are dropped until the parser recovers at a statement boundary, so
+// a single mistake is reported only once.
+func (p *Parser) report(d Diagnostic) {
+	if d.Severity == SeverityError {
+		if p.panicking {
+			return
+		}
+		p.panicking = true
+	}
+	p.diagnostics = append(p.diagnostics, d)
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	// Adds an error message to the parser's errors slice.
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	// Adds an error diagnostic pointing at the unexpected peek token.
+	p.report(Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeUnexpectedToken,
+		Message:  fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type),
+		Span:     p.peekToken.Span,
+		Expected: []token.TokenType{t},
+		Actual:   p.peekToken.Type,
+		Hint:     closingHint(t, p.peekToken.Type),
+	})
+}
+
+// closingHint suggests a fix when input ends before a delimiter is closed.
+func closingHint(expected, actual token.TokenType) string {
+	if actual != token.EOF {
+		return ""
+	}
+	switch expected {
+	case token.RPAREN, token.RBRACE, token.RBRACKET:
+		return fmt.Sprintf("add the missing %q", string(expected))
+	}
+	return ""
 }
 
 func (p *Parser) nextToken() {
 	// Advance the lexer to the next token and update curToken and peekToken.
 	p.curToken = p.peekToken
 	p.peekToken = p.lexer.NextToken()
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		p.depth--
+	}
 }
 
 // ParseProgram parses the input tokens into an AST Program.
@@ -118,43 +215,127 @@ func (p *Parser) ParseProgram() *ast.Program {
 	// This loop will parse all statements in the program.
 	// Each statement is parsed and added to the program's Statements slice.
 	for p.curToken.Type != token.EOF {
-		stmt := p.parseStatement()
-		if stmt != nil {
-			program.Statements = append(program.Statements, stmt)
-		}
+		stmt, _ := p.parseStatementWithRecovery()
+		program.Statements = append(program.Statements, stmt)
 		p.nextToken()
 	}
 
 	return program
 }
 
+// statementStarts holds the tokens that can only begin a statement. The
+// parser resynchronizes on them after an error.
+var statementStarts = map[token.TokenType]bool{
+	token.LET:      true,
+	token.RETURN:   true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+}
+
+// parseStatementWithRecovery parses a statement and, if an error was
+// reported while doing so, skips the rest of it so parsing can resume. A
+// statement that could not be parsed at all is replaced by an
+// ast.BadStatement. The result reports whether recovery stopped on a '}'
+// that closes an enclosing block.
+func (p *Parser) parseStatementWithRecovery() (ast.Statement, bool) {
+	from := p.curToken
+	base := p.depth
+	switch p.curToken.Type {
+	case token.LBRACE:
+		base--
+	case token.RBRACE:
+		base++
+	}
+
+	stmt := p.parseStatement()
+	if !p.panicking {
+		return stmt, false
+	}
+
+	closed := p.synchronize(base)
+	p.panicking = false
+	if stmt == nil {
+		stmt = &ast.BadStatement{From: from, To: p.curToken}
+	}
+	return stmt, closed
+}
+
+// synchronize advances to the last token of the statement being parsed at
+// brace depth base: a ';', or the token before a '}', EOF or a keyword that
+// starts a new statement. Braced blocks inside the statement are skipped
+// whole. It reports whether it stopped on a '}' closing an enclosing block.
+func (p *Parser) synchronize(base int) bool {
+	for {
+		if p.depth < base {
+			return true
+		}
+		if p.curTokenIs(token.EOF) {
+			return false
+		}
+		if p.depth == base {
+			if p.curTokenIs(token.SEMICOLON) ||
+				p.peekTokenIs(token.RBRACE) ||
+				p.peekTokenIs(token.EOF) ||
+				statementStarts[p.peekToken.Type] {
+				return false
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// consumeSemicolon consumes the optional ';' ending a statement. After an
+// error it is left for synchronize, which must see where parsing stopped.
+func (p *Parser) consumeSemicolon() {
+	if p.peekTokenIs(token.SEMICOLON) && !p.panicking {
+		p.nextToken()
+	}
+}
+
+// badExpression returns a placeholder for an expression that failed to parse
+// somewhere between from and the current token.
+func (p *Parser) badExpression(from token.Token) ast.Expression {
+	return &ast.BadExpression{From: from, To: p.curToken}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		stmt := &ast.BreakStatement{Token: p.curToken}
+		p.consumeSemicolon()
+		return stmt
+	case token.CONTINUE:
+		stmt := &ast.ContinueStatement{Token: p.curToken}
+		p.consumeSemicolon()
+		return stmt
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+func (p *Parser) parseReturnStatement() ast.Statement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
 	p.nextToken()
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	// TODO: We're skipping the expression until we encounter a semicolon.
-	for !p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
+	p.consumeSemicolon()
 
 	return stmt
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+func (p *Parser) parseLetStatement() ast.Statement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
 	// Expect the next token to be an identifier.
@@ -173,16 +354,80 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
-	// If the next token is a semicolon, consume it.
-	for p.peekTokenIs(token.SEMICOLON) {
-		// Advance to the next token until we reach a semicolon.
-		p.nextToken()
-	}
+	p.consumeSemicolon()
 
 	// Return the completed LetStatement.
 	return stmt
 }
 
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken() // Move to the condition expression.
+
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+	p.consumeSemicolon()
+
+	return stmt
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Variables = append(stmt.Variables, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken() // Consume the comma.
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Variables = append(stmt.Variables, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+	stmt.In = p.curToken
+
+	p.nextToken() // Move to the iterable expression.
+
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+	p.consumeSemicolon()
+
+	return stmt
+}
+
 func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Type == t
 }
@@ -217,9 +462,7 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	// Parse the expression and assign it to the Expression field of the statement.
 	stmt.Expression = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken() // Consume the semicolon.
-	}
+	p.consumeSemicolon()
 
 	return stmt
 }
@@ -230,11 +473,11 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
+		return p.badExpression(p.curToken)
 	}
 	leftExp := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() && !p.panicking {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -256,11 +499,39 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	digits := normalizeDigits(p.curToken.Literal)
+	value, err := parseInteger(digits)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		p.report(Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeInvalidInteger,
+			Message:  fmt.Sprintf("could not parse %q as integer", p.curToken.Literal),
+			Span:     p.curToken.Span,
+			Actual:   p.curToken.Type,
+			Hint:     numberHint(digits, err),
+		})
+		return p.badExpression(p.curToken)
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	digits := normalizeDigits(p.curToken.Literal)
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		p.report(Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeInvalidFloat,
+			Message:  fmt.Sprintf("could not parse %q as float", p.curToken.Literal),
+			Span:     p.curToken.Span,
+			Actual:   p.curToken.Type,
+			Hint:     numberHint(digits, err),
+		})
+		return p.badExpression(p.curToken)
 	}
 
 	lit.Value = value
@@ -268,8 +539,13 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.report(Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeNoPrefixParseFn,
+		Message:  fmt.Sprintf("no prefix parse function for %s found", t),
+		Span:     p.curToken.Span,
+		Actual:   t,
+	})
 }
 
 func (p *Parser) parserExpression(precedence int) ast.Expression {
@@ -326,11 +602,45 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if precedence == POWER {
+		// ** is right associative: 2 ** 3 ** 2 is 2 ** (3 ** 2).
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
+
+// parseAssignExpression parses an assignment. Assignment is right
+// associative, so `a = b = c` assigns c to b and then to a.
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	default:
+		p.report(Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeInvalidTarget,
+			Message:  fmt.Sprintf("cannot assign to %s", target.String()),
+			Span:     token.Span{Start: target.Pos(), End: target.End()},
+			Actual:   p.curToken.Type,
+			Hint:     "only variables and index expressions such as xs[0] can be assigned to",
+		})
+		return p.badExpression(p.curToken)
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGNMENT - 1)
+
+	return expression
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{
 		Token: p.curToken,
@@ -339,11 +649,12 @@ func (p *Parser) parseBoolean() ast.Expression {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	from := p.curToken
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpression(from)
 	}
 
 	return exp
@@ -355,7 +666,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	}
 
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	p.nextToken() // Move to the condition expression.
@@ -363,11 +674,11 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	expression.Condition = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	expression.Consequence = p.parseBlockStatement()
@@ -376,7 +687,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 		p.nextToken() // Consume the ELSE token.
 
 		if !p.expectPeek(token.LBRACE) {
-			return nil
+			return p.badExpression(expression.Token)
 		}
 
 		expression.Alternative = p.parseBlockStatement()
@@ -393,13 +704,28 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 	// Continue parsing statements until we reach the end of the block.
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
-		if stmt != nil {
-			block.Statements = append(block.Statements, stmt)
+		stmt, closed := p.parseStatementWithRecovery()
+		block.Statements = append(block.Statements, stmt)
+		if closed {
+			break
 		}
 		p.nextToken()
 	}
 
+	if p.curTokenIs(token.RBRACE) {
+		block.Rbrace = p.curToken
+	} else {
+		p.report(Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeUnexpectedToken,
+			Message:  fmt.Sprintf("expected %s to close block, got %s instead", token.RBRACE, p.curToken.Type),
+			Span:     p.curToken.Span,
+			Expected: []token.TokenType{token.RBRACE},
+			Actual:   p.curToken.Type,
+			Hint:     fmt.Sprintf("the block was opened at %s", block.Token.Span.Start),
+		})
+	}
+
 	return block
 }
 
@@ -409,13 +735,15 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	}
 
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(lit.Token)
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return p.badExpression(lit.Token)
+	}
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(lit.Token)
 	}
 
 	lit.Body = p.parseBlockStatement()
@@ -423,53 +751,69 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parseFunctionParameters parses a parameter list such as
+// (x, y = 10, ...rest) into lit and reports whether it succeeded.
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
+	lit.Defaults = []ast.Expression{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken() // Consume the closing parenthesis.
-		return identifiers
+		return true
 	}
 
-	p.nextToken() // Move to the first parameter.
-
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
-
 	// Continue parsing parameters until we reach a closing parenthesis or EOF.
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken() // Consume the comma.
-		p.nextToken() // Move to the next parameter.
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken() // Consume the '...'.
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if p.peekTokenIs(token.COMMA) {
+				p.parameterError(p.peekToken, "rest parameter must be the last parameter")
+				return false
+			}
+			break
+		}
 
+		// Move to the next parameter.
+		if !p.expectPeek(token.IDENT) {
+			return false
+		}
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
-	}
-
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
-
-	return identifiers
-}
 
-func (p *Parser) parserFunctionLiteral() ast.Expression {
-	lit := &ast.FunctionLiteral{
-		Token: p.curToken,
-	}
-
-	if !p.expectPeek(token.LPAREN) {
-		return nil
-	}
+		var value ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken() // Consume the '='.
+			p.nextToken() // Move to the default value.
+			value = p.parseExpression(LOWEST)
+		} else if len(lit.Defaults) > 0 && lit.Defaults[len(lit.Defaults)-1] != nil {
+			p.parameterError(p.curToken,
+				fmt.Sprintf("parameter %s without a default follows a parameter with a default", ident.Value))
+			return false
+		}
 
-	lit.Parameters = p.parseFunctionParameters()
+		lit.Parameters = append(lit.Parameters, ident)
+		lit.Defaults = append(lit.Defaults, value)
 
-	if !p.expectPeek(token.LBRACE) {
-		return nil
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken() // Consume the comma.
 	}
 
-	lit.Body = p.parseBlockStatement()
+	return p.expectPeek(token.RPAREN)
+}
 
-	return lit
+func (p *Parser) parameterError(tok token.Token, msg string) {
+	p.report(Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeInvalidParameter,
+		Message:  msg,
+		Span:     tok.Span,
+		Actual:   tok.Type,
+	})
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -479,6 +823,9 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	}
 
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if p.curTokenIs(token.RPAREN) {
+		exp.Rparen = p.curToken
+	}
 
 	return exp
 }
@@ -527,15 +874,78 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression { //
 	return list
 }
 
+// parseIllegal reports the problem the lexer described in an ILLEGAL token.
+func (p *Parser) parseIllegal() ast.Expression {
+	p.report(Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeIllegalToken,
+		Message:  p.curToken.Literal,
+		Span:     p.curToken.Span,
+		Actual:   token.ILLEGAL,
+	})
+	return p.badExpression(p.curToken)
+}
+
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	expression := &ast.SpreadExpression{Token: p.curToken}
+
+	p.nextToken() // Move to the expression being spread.
+
+	expression.Value = p.parseExpression(PREFIX)
+
+	return expression
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	// Create a new StringLiteral node with the current token.
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseInterpolatedString parses a string with embedded expressions, which
+// the lexer splits into text tokens around the tokens of each expression.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+
+	for {
+		// The current token is the text before an interpolation.
+		str.Parts = append(str.Parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+
+		p.nextToken() // Move to the embedded expression.
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		switch p.peekToken.Type {
+		case token.TEMPLATE_MIDDLE:
+			p.nextToken()
+		case token.TEMPLATE_TAIL:
+			p.nextToken()
+			str.Parts = append(str.Parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+			return str
+		case token.ILLEGAL:
+			// The rest of the string is malformed, e.g. never closed.
+			p.nextToken()
+			return p.parseIllegal()
+		default:
+			p.report(Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeUnexpectedToken,
+				Message:  fmt.Sprintf("expected } to end ${ in string, got %s instead", p.peekToken.Type),
+				Span:     p.peekToken.Span,
+				Expected: []token.TokenType{token.TEMPLATE_MIDDLE, token.TEMPLATE_TAIL},
+				Actual:   p.peekToken.Type,
+				Hint:     fmt.Sprintf("the string starts at %s", str.Pos()),
+			})
+			return p.badExpression(str.Token)
+		}
+	}
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.curTokenIs(token.RBRACKET) {
+		array.Rbracket = p.curToken
+	}
 
 	return array
 }
@@ -548,8 +958,9 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp.Index = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(token.RBRACKET) {
-		return nil
+		return p.badExpression(exp.Token)
 	}
+	exp.Rbracket = p.curToken
 
 	return exp
 }
@@ -562,19 +973,20 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		key := p.parseExpression(LOWEST)
 		if !p.expectPeek(token.COLON) {
-			return nil
+			return p.badExpression(hash.Token)
 		}
 
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
-			return nil
+			return p.badExpression(hash.Token)
 		}
 	}
 
 	if !p.expectPeek(token.RBRACE) {
-		return nil
+		return p.badExpression(hash.Token)
 	}
+	hash.Rbrace = p.curToken
 	return hash
 }
@@ -3,6 +3,7 @@ package parser
 import (
 	"bangu/ast"
 	"bangu/lexer"
+	"bangu/token"
 	"fmt"
 	"testing"
 )
@@ -142,6 +143,119 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff", 255},
+		{"0XFF", 255},
+		{"0x_dead_beef", 0xdeadbeef},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"0B1_0", 2},
+		{"1_000_000", 1000000},
+		{"010", 10},
+		{"09", 9},
+		{"0_7", 7},
+		{"00", 0},
+		{"9223372036854775807", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("%q: literal.Value not %d. got=%d", tt.input, tt.expected, literal.Value)
+		}
+	}
+}
+
+func TestNumberLiteralDiagnostics(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCode     Code
+		expectedPosition string
+		expectedHint     string
+	}{
+		{"let x = 0xfg;", CodeInvalidInteger, "1:9", `'g' is not a valid digit in a hexadecimal literal`},
+		{"0b102", CodeInvalidInteger, "1:1", `'2' is not a valid digit in a binary literal`},
+		{"1 + 12abc", CodeInvalidInteger, "1:5", `'a' is not a valid digit in a decimal literal`},
+		{"0x", CodeInvalidInteger, "1:1", "a hexadecimal literal needs at least one digit"},
+		{"1__000", CodeInvalidInteger, "1:1", "underscores may only appear between digits, as in 1_000_000"},
+		{"1_000_", CodeInvalidInteger, "1:1", "underscores may only appear between digits, as in 1_000_000"},
+		{"1_.5", CodeInvalidFloat, "1:1", "underscores may only appear between digits, as in 1_000_000"},
+		{"0x1__0", CodeInvalidInteger, "1:1", "underscores may only appear between digits, as in 1_000_000"},
+		{"1e", CodeInvalidFloat, "1:1", "an exponent needs digits, as in 1e9 or 2.5e-3"},
+		{"0x1_0000_0000_0000_0000", CodeInvalidInteger, "1:1", "the value does not fit in 64 bits"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		diagnostics := p.Diagnostics()
+		if len(diagnostics) != 1 {
+			t.Errorf("input %q: expected 1 diagnostic, got=%d", tt.input, len(diagnostics))
+			continue
+		}
+
+		d := diagnostics[0]
+		if d.Code != tt.expectedCode {
+			t.Errorf("input %q: code wrong. expected=%s, got=%s", tt.input, tt.expectedCode, d.Code)
+		}
+		if d.Span.Start.String() != tt.expectedPosition {
+			t.Errorf("input %q: position wrong. expected=%s, got=%s", tt.input, tt.expectedPosition, d.Span.Start)
+		}
+		if d.Hint != tt.expectedHint {
+			t.Errorf("input %q: hint wrong. expected=%q, got=%q", tt.input, tt.expectedHint, d.Hint)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{"1e9;", 1e9},
+		{"2.5e-3;", 2.5e-3},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program has not enough statements. got=%d", len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	PrefixTests := []struct {
 		input    string
@@ -285,6 +399,30 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
 		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
 		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"x = a + b * c", "x = (a + (b * c))"},
+		{"a = b = c", "a = b = c"},
+		{"x += y == z", "x += (y == z)"},
+		{"xs[i] *= 2", "(xs[i]) *= 2"},
+		{"h[k][0] = f(x)", "((h[k])[0]) = f(x)"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a < b == c >= d", "((a < b) == (c >= d))"},
+		{"a <= b && c != d", "((a <= b) && (c != d))"},
+		{"x = a || b", "x = (a || b)"},
+		{"a * b % c", "((a * b) % c)"},
+		{"a + b % c", "(a + (b % c))"},
+		{"a ** b ** c", "(a ** (b ** c))"},
+		{"-a ** b", "(-(a ** b))"},
+		{"a ** -b", "(a ** (-b))"},
+		{"a * b ** c", "(a * (b ** c))"},
+		{"a ** b[0]", "(a ** (b[0]))"},
+		{"a | b & c", "(a | (b & c))"},
+		{"a ^ b << c", "(a ^ (b << c))"},
+		{"a & b == c", "((a & b) == c)"},
+		{"a >> b &^ c", "((a >> b) &^ c)"},
+		{"~a & b", "((~a) & b)"},
+		{"a < b << c", "(a < (b << c))"},
+		{"a && b | c", "(a && (b | c))"},
 	}
 
 	for _, tt := range tests {
@@ -422,6 +560,76 @@ func TestIfExpression(t *testing.T) {
 	}
 }
 
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < y) { x; break; continue; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 3 {
+		t.Fatalf("body is not 3 statements. got=%d", len(stmt.Body.Statements))
+	}
+	if _, ok := stmt.Body.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[1] is not ast.BreakStatement. got=%T", stmt.Body.Statements[1])
+	}
+	if _, ok := stmt.Body.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[2] is not ast.ContinueStatement. got=%T", stmt.Body.Statements[2])
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input     string
+		variables []string
+		expected  string
+	}{
+		{"for (x in xs) { x }", []string{"x"}, "for x in xs x"},
+		{"for (k, v in {1: 2}) { v };", []string{"k", "v"}, "for k, v in {1:2} v"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+		}
+
+		if len(stmt.Variables) != len(tt.variables) {
+			t.Fatalf("wrong number of loop variables. want %d, got=%d", len(tt.variables), len(stmt.Variables))
+		}
+		for i, name := range tt.variables {
+			testLiteralExpression(t, stmt.Variables[i], name)
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. want %q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := "fn(x, y) { x + y; }"
 
@@ -493,6 +701,50 @@ func TestFunctionParameterParsing(t *testing.T) {
 
 }
 
+func TestDefaultAndRestParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(x, y = 10) { x };", "fn(x, y = 10) x"},
+		{"fn(x = 1 + 2, ...rest) { rest };", "fn(x = (1 + 2), ...rest) rest"},
+		{"fn(...args) { args };", "fn(...args) args"},
+		{"f(...args);", "f(...args)"},
+		{"f(1, ...g(x), 2);", "f(1, ...g(x), 2)"},
+		{"[0, ...xs];", "[0, ...xs]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	invalid := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(...rest, x) {}", "rest parameter must be the last parameter"},
+		{"fn(x = 1, y) {}", "parameter y without a default follows a parameter with a default"},
+	}
+
+	for _, tt := range invalid {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected error %q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -546,6 +798,103 @@ func TestStringliteralExpression(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStringParsing(t *testing.T) {
+	input := `"Hello ${name}, you are ${age + 1}"`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	if len(str.Parts) != 5 {
+		t.Fatalf("wrong number of parts. want 5, got=%d", len(str.Parts))
+	}
+	testIdentifier(t, str.Parts[1], "name")
+	testInfixExpression(t, str.Parts[3], "age", "+", 1)
+
+	if str.Parts[3].Pos().String() != "1:27" {
+		t.Errorf("embedded expression position wrong. want 1:27, got=%s", str.Parts[3].Pos())
+	}
+	if str.End().Offset != len(input) {
+		t.Errorf("str.End() wrong. want offset %d, got=%d", len(input), str.End().Offset)
+	}
+	if str.String() != `"Hello ${name}, you are ${(age + 1)}"` {
+		t.Errorf("str.String() wrong. got=%q", str.String())
+	}
+}
+
+func TestBanglaKeywords(t *testing.T) {
+	defer func(v token.Vocabulary) { ast.KeywordVocabulary = v }(ast.KeywordVocabulary)
+	token.UseVocabularies(token.English, token.Bangla)
+	defer token.UseVocabularies(token.English)
+
+	input := "ধরি বড় = কাজ(ক, খ) { যদি (ক > খ) { ফেরত ক; } নাহলে { খ } }; " +
+		"প্রতিটি (x মধ্যে [১, ২]) { থামো; } যতক্ষণ (সত্য) { চালাও; }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	tests := []struct {
+		vocabulary token.Vocabulary
+		expected   string
+	}{
+		{token.English, "let বড় = fn(ক, খ) if (ক > খ) return ক; else খ;" +
+			"for x in [১, ২] break;while true continue;"},
+		{token.Bangla, "ধরি বড় = কাজ(ক, খ) যদি (ক > খ) ফেরত ক; নাহলে খ;" +
+			"প্রতিটি x মধ্যে [১, ২] থামো;যতক্ষণ সত্য চালাও;"},
+	}
+
+	for _, tt := range tests {
+		ast.KeywordVocabulary = tt.vocabulary
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong.\nwant=%q\n got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestBengaliDigits(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"১২৩", "123"},
+		{"১_০০০", "1000"},
+		{"৩.৫", "3.5"},
+		{"1২", "12"},
+		{"০১০", "10"},
+		{"০৮", "8"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		var got string
+		switch lit := stmt.Expression.(type) {
+		case *ast.IntegerLiteral:
+			got = fmt.Sprintf("%d", lit.Value)
+		case *ast.FloatLiteral:
+			got = fmt.Sprintf("%g", lit.Value)
+		default:
+			t.Fatalf("%q: exp is not a number literal. got=%T", tt.input, stmt.Expression)
+		}
+		if got != tt.expected {
+			t.Errorf("%q: value wrong. want=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
 func TestParsingArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 
@@ -689,3 +1038,168 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 	}
 
 }
+
+func TestNodePositions(t *testing.T) {
+	input := `let add = fn(x, y) {
+  x + y;
+};
+add(1, [2, 3][0]);`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	let := program.Statements[0].(*ast.LetStatement)
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	body := let.Value.(*ast.FunctionLiteral).Body
+
+	tests := []struct {
+		node          ast.Node
+		expectedStart string
+		expectedEnd   string
+	}{
+		{program, "1:1", "4:18"},
+		{let, "1:1", "3:2"},
+		{let.Value, "1:11", "3:2"},
+		{body, "1:20", "3:2"},
+		{body.Statements[0], "2:3", "2:8"},
+		{call, "4:1", "4:18"},
+		{call.Arguments[1], "4:8", "4:17"},
+	}
+
+	for i, tt := range tests {
+		if tt.node.Pos().String() != tt.expectedStart {
+			t.Errorf("tests[%d] - Pos wrong for %T. expected=%s, got=%s",
+				i, tt.node, tt.expectedStart, tt.node.Pos())
+		}
+		if tt.node.End().String() != tt.expectedEnd {
+			t.Errorf("tests[%d] - End wrong for %T. expected=%s, got=%s",
+				i, tt.node, tt.expectedEnd, tt.node.End())
+		}
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCode     Code
+		expectedMessage  string
+		expectedPosition string
+		expectedActual   token.TokenType
+	}{
+		{"let x = (5", CodeUnexpectedToken, "expected next token to be ), got EOF instead", "1:11", token.EOF},
+		{"let = 5;", CodeUnexpectedToken, "expected next token to be IDENT, got = instead", "1:5", token.ASSIGN},
+		{"1 +;", CodeNoPrefixParseFn, "no prefix parse function for ; found", "1:4", token.SEMICOLON},
+		{"99999999999999999999", CodeInvalidInteger, `could not parse "99999999999999999999" as integer`, "1:1", token.INT},
+		{"f(x) = 1", CodeInvalidTarget, "cannot assign to f(x)", "1:1", token.ASSIGN},
+		{`"a ${x y} b"`, CodeUnexpectedToken, "expected } to end ${ in string, got IDENT instead", "1:8", token.IDENT},
+		{`"a ${x} b`, CodeIllegalToken, "unterminated string", "1:7", token.ILLEGAL},
+		{"1 + x -= 1", CodeInvalidTarget, "cannot assign to (1 + x)", "1:1", token.MINUS_ASSIGN},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		diagnostics := p.Diagnostics()
+		if len(diagnostics) == 0 {
+			t.Errorf("input %q produced no diagnostics", tt.input)
+			continue
+		}
+
+		d := diagnostics[0]
+		if d.Severity != SeverityError {
+			t.Errorf("input %q: severity wrong. got=%s", tt.input, d.Severity)
+		}
+		if d.Code != tt.expectedCode {
+			t.Errorf("input %q: code wrong. expected=%s, got=%s", tt.input, tt.expectedCode, d.Code)
+		}
+		if d.Message != tt.expectedMessage {
+			t.Errorf("input %q: message wrong. expected=%q, got=%q", tt.input, tt.expectedMessage, d.Message)
+		}
+		if d.Span.Start.String() != tt.expectedPosition {
+			t.Errorf("input %q: position wrong. expected=%s, got=%s", tt.input, tt.expectedPosition, d.Span.Start)
+		}
+		if d.Actual != tt.expectedActual {
+			t.Errorf("input %q: actual token wrong. expected=%s, got=%s", tt.input, tt.expectedActual, d.Actual)
+		}
+		if p.Errors()[0] != tt.expectedMessage {
+			t.Errorf("input %q: Errors()[0] wrong. expected=%q, got=%q", tt.input, tt.expectedMessage, p.Errors()[0])
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+		expected       string
+	}{
+		{
+			"let = 5; let y = 3;",
+			[]string{"expected next token to be IDENT, got = instead"},
+			"<bad statement>let y = 3;",
+		},
+		{
+			"let x = (5 + ; let y = 2; y",
+			[]string{"no prefix parse function for ; found"},
+			"let x = <bad expression>;let y = 2;y",
+		},
+		{
+			"let f = fn(x) { x + }; let z = 1;",
+			[]string{"no prefix parse function for } found"},
+			"let f = fn(x) (x + <bad expression>);let z = 1;",
+		},
+		{
+			"if (x { y } let a = 1;",
+			[]string{"expected next token to be ), got { instead"},
+			"<bad expression>let a = 1;",
+		},
+		{
+			"let a = 1 +; let b = ; let c = 3;",
+			[]string{
+				"no prefix parse function for ; found",
+				"no prefix parse function for ; found",
+			},
+			"let a = (1 + <bad expression>);let b = <bad expression>;let c = 3;",
+		},
+		{
+			"if (a) { return 1 }",
+			[]string{},
+			"if a return 1;",
+		},
+		{
+			"fn(x) { x",
+			[]string{"expected } to close block, got EOF instead"},
+			"fn(x) x",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("input %q: wrong number of errors. expected=%d, got=%d (%q)",
+				tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+		for i, msg := range tt.expectedErrors {
+			if errors[i] != msg {
+				t.Errorf("input %q: errors[%d] wrong. expected=%q, got=%q", tt.input, i, msg, errors[i])
+			}
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("input %q: program wrong. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
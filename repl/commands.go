@@ -0,0 +1,169 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bangu/ast"
+	"bangu/lexer"
+	"bangu/object"
+	"bangu/parser"
+	"bangu/token"
+)
+
+// A command is a REPL meta-command, typed as a colon followed by its name
+// and argument, as in `:type 1 + 2`.
+type command struct {
+	arg  string // the argument the command takes, as shown by :help
+	help string
+	run  func(s *session, arg string)
+}
+
+// commands maps each command name to its command. It is filled in by init,
+// since :help refers to it.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"env":    {"", "list the variables bound in the session, with their types", (*session).listEnv},
+		"type":   {"EXPR", "evaluate EXPR and show the type of its value", (*session).showType},
+		"ast":    {"EXPR", "show the syntax tree EXPR parses to", (*session).showAST},
+		"tokens": {"EXPR", "show the tokens EXPR is read as", (*session).showTokens},
+		"time":   {"EXPR", "evaluate EXPR and show how long it took", (*session).timeEval},
+		"load":   {"FILE", "run the script FILE in the session", (*session).load},
+		"reset":  {"", "forget every variable bound in the session", (*session).reset},
+		"save":   {"FILE", "save the inputs evaluated in the session as the script FILE", (*session).save},
+		"help":   {"", "list the commands", (*session).help},
+
+		"load-session": {"FILE", "replace the session with the one saved in FILE", (*session).loadSession},
+	}
+}
+
+// runCommand runs the command typed as line.
+func (s *session) runCommand(line string) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), ":"), " ")
+	arg = strings.TrimSpace(arg)
+
+	cmd, ok := commands[name]
+	switch {
+	case !ok:
+		fmt.Fprintf(s.errOut, "unknown command :%s; type :help for the list of commands\n", name)
+	case cmd.arg != "" && arg == "":
+		fmt.Fprintf(s.errOut, "usage: :%s %s\n", name, cmd.arg)
+	case cmd.arg == "" && arg != "":
+		fmt.Fprintf(s.errOut, "usage: :%s takes no argument\n", name)
+	default:
+		cmd.run(s, arg)
+	}
+}
+
+func (s *session) listEnv(string) {
+	names := s.env.Names()
+	if len(names) == 0 {
+		io.WriteString(s.out, "no variables are bound\n")
+		return
+	}
+
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		value, _ := s.env.Get(name)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, value.Type(), summary(value))
+	}
+	w.Flush()
+}
+
+// summary returns the Inspect form of value, shortened to the signature
+// for functions, whose bodies would not fit on a line.
+func summary(value object.Object) string {
+	if fn, ok := value.(*object.Function); ok {
+		return ast.Keyword(token.FUNCTION) + "(" + ast.ParametersString(fn.Parameters, fn.Defaults, fn.Rest) + ")"
+	}
+	return value.Inspect()
+}
+
+func (s *session) showType(arg string) {
+	value, ok := s.run(arg)
+	if ok && value != nil {
+		io.WriteString(s.out, string(value.Type())+"\n")
+	}
+}
+
+func (s *session) showAST(arg string) {
+	p := parser.New(lexer.New(arg))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.errOut, arg, p.Diagnostics())
+		return
+	}
+	ast.Fprint(s.out, program)
+}
+
+func (s *session) showTokens(arg string) {
+	l := lexer.New(arg)
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(w, "%s\t%s\t%q\n", tok.Span.Start, tok.Type, tok.Literal)
+	}
+	w.Flush()
+}
+
+func (s *session) timeEval(arg string) {
+	start := time.Now()
+	value, ok := s.run(arg)
+	elapsed := time.Since(start)
+	if !ok {
+		return
+	}
+
+	if value != nil {
+		io.WriteString(s.out, value.Inspect()+"\n")
+	}
+	fmt.Fprintf(s.out, "took %s\n", elapsed)
+}
+
+func (s *session) load(path string) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(s.errOut, "cannot load %s: %s\n", path, err)
+		return
+	}
+
+	if _, ok := s.evaluate(path, string(source)); ok {
+		s.record("// :load " + path + "\n" + withoutShebang(string(source)))
+	}
+}
+
+func (s *session) reset(string) {
+	s.clear()
+}
+
+// withoutShebang removes the #! line a script may start with, which is
+// only allowed at the start of a file.
+func withoutShebang(source string) string {
+	if strings.HasPrefix(source, "#!") {
+		_, rest, _ := strings.Cut(source, "\n")
+		return rest
+	}
+	return source
+}
+
+func (s *session) help(string) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		cmd := commands[name]
+		usage := strings.TrimSpace(":" + name + " " + cmd.arg)
+		fmt.Fprintf(w, "%s\t%s\n", usage, cmd.help)
+	}
+	w.Flush()
+}
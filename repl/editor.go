@@ -0,0 +1,365 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted is returned by readLine when Ctrl-C abandons the line.
+var errInterrupted = errors.New("interrupted")
+
+// A lineReader reads the lines typed into the REPL.
+type lineReader interface {
+	// readLine shows prompt and returns the next line without its newline,
+	// or io.EOF at the end of the input.
+	readLine(prompt string) (string, error)
+}
+
+// newLineReader returns a line editor when in and out are both a terminal,
+// and otherwise reads plain lines from in. names lists the identifiers
+// offered by tab completion.
+func newLineReader(in io.Reader, out io.Writer, names func() []string) lineReader {
+	inFile, inOK := in.(*os.File)
+	outFile, outOK := out.(*os.File)
+	if inOK && outOK && isTerminal(int(inFile.Fd())) && isTerminal(int(outFile.Fd())) {
+		return &editor{
+			fd:      int(inFile.Fd()),
+			in:      bufio.NewReader(inFile),
+			out:     out,
+			history: loadHistory(historyPath()),
+			names:   names,
+		}
+	}
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// scannerReader reads lines from input that is not a terminal, such as a
+// pipe.
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (s *scannerReader) readLine(prompt string) (string, error) {
+	io.WriteString(s.out, prompt)
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return s.scanner.Text(), nil
+}
+
+// editor reads lines from a terminal in raw mode, supporting cursor
+// movement, history and tab completion:
+//
+//	Left, Right, Ctrl-B, Ctrl-F   move the cursor
+//	Home, End, Ctrl-A, Ctrl-E     move to the start or end of the line
+//	Up, Down, Ctrl-P, Ctrl-N      step through the history
+//	Backspace, Delete             delete a character
+//	Ctrl-K, Ctrl-U, Ctrl-W        delete to the end, to the start, a word
+//	Tab                           complete the identifier before the cursor
+//	Ctrl-L                        clear the screen
+//	Ctrl-C                        abandon the input
+//	Ctrl-D                        end the session, on an empty line
+//
+// The line is redrawn on a single terminal row; lines wider than the
+// terminal are left to wrap as the terminal sees fit.
+type editor struct {
+	fd      int
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+	names   func() []string
+}
+
+// lineState is the line being edited.
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int // cursor position in buf
+
+	// index is the history entry shown, or len(history.entries) for the
+	// line being typed, which is kept in pending while browsing.
+	index   int
+	pending []rune
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	s := &lineState{prompt: prompt, index: len(e.history.entries)}
+	e.refresh(s)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			io.WriteString(e.out, "\n")
+			line := string(s.buf)
+			e.history.add(line)
+			return line, nil
+		case ctrl('C'):
+			io.WriteString(e.out, "^C\n")
+			return "", errInterrupted
+		case ctrl('D'):
+			if len(s.buf) == 0 {
+				return "", io.EOF
+			}
+			s.delete()
+		case 127, ctrl('H'):
+			s.backspace()
+		case ctrl('A'):
+			s.pos = 0
+		case ctrl('E'):
+			s.pos = len(s.buf)
+		case ctrl('B'):
+			s.left()
+		case ctrl('F'):
+			s.right()
+		case ctrl('K'):
+			s.buf = s.buf[:s.pos]
+		case ctrl('U'):
+			s.buf = s.buf[s.pos:]
+			s.pos = 0
+		case ctrl('W'):
+			s.deleteWord()
+		case ctrl('P'):
+			e.browse(s, -1)
+		case ctrl('N'):
+			e.browse(s, 1)
+		case ctrl('L'):
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case '\t':
+			e.complete(s)
+		case '\x1b':
+			e.escape(s)
+		default:
+			if unicode.IsPrint(r) || unicode.Is(unicode.Mn, r) {
+				s.insert(r)
+			}
+		}
+		e.refresh(s)
+	}
+}
+
+// ctrl returns the character typed by holding Ctrl with key.
+func ctrl(key rune) rune {
+	return key & 0x1f
+}
+
+// escape handles the rest of an escape sequence, such as "\x1b[A" for the
+// up arrow. A terminal sends a sequence in one write, so an ESC with nothing
+// read after it is taken as the Esc key on its own, and does nothing. So
+// is an ESC followed by anything but '[' or 'O', which is left to be read
+// as the next key.
+func (e *editor) escape(s *lineState) {
+	if e.in.Buffered() == 0 {
+		return
+	}
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return
+	}
+	if r != '[' && r != 'O' {
+		e.in.UnreadRune()
+		return
+	}
+
+	// A control sequence is parameter characters ended by a final byte
+	// in the range '@' to '~'.
+	var param strings.Builder
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return
+		}
+		if '@' <= r && r <= '~' {
+			break
+		}
+		param.WriteRune(r)
+	}
+
+	switch r {
+	case 'A':
+		e.browse(s, -1)
+	case 'B':
+		e.browse(s, 1)
+	case 'C':
+		s.right()
+	case 'D':
+		s.left()
+	case 'H':
+		s.pos = 0
+	case 'F':
+		s.pos = len(s.buf)
+	case '~':
+		switch param.String() {
+		case "1", "7":
+			s.pos = 0
+		case "4", "8":
+			s.pos = len(s.buf)
+		case "3":
+			s.delete()
+		}
+	}
+}
+
+// browse moves delta entries through the history, replacing the line.
+func (e *editor) browse(s *lineState, delta int) {
+	entries := e.history.entries
+	index := s.index + delta
+	if index < 0 || index > len(entries) {
+		return
+	}
+
+	if s.index == len(entries) {
+		s.pending = s.buf
+	}
+	s.index = index
+	if index == len(entries) {
+		s.buf = s.pending
+	} else {
+		s.buf = []rune(entries[index])
+	}
+	s.pos = len(s.buf)
+}
+
+// complete completes the identifier before the cursor, listing the
+// candidates when the completion is ambiguous.
+func (e *editor) complete(s *lineState) {
+	buf, pos, candidates := complete(s.buf, s.pos, e.names())
+	if pos == s.pos && len(candidates) > 1 {
+		io.WriteString(e.out, "\n"+strings.Join(candidates, "  ")+"\n")
+	}
+	s.buf, s.pos = buf, pos
+}
+
+// refresh redraws the prompt and line and puts the cursor in place.
+func (e *editor) refresh(s *lineState) {
+	var b strings.Builder
+	b.WriteString("\r" + s.prompt + string(s.buf) + "\x1b[K\r")
+	if n := displayWidth([]rune(s.prompt)) + displayWidth(s.buf[:s.pos]); n > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", n)
+	}
+	io.WriteString(e.out, b.String())
+}
+
+// displayWidth returns the number of terminal columns taken by text,
+// counting combining marks such as the vowel signs of Bangla as taking none.
+func displayWidth(text []rune) int {
+	width := 0
+	for _, r := range text {
+		if !unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			width++
+		}
+	}
+	return width
+}
+
+func (s *lineState) insert(r rune) {
+	s.buf = append(s.buf[:s.pos], append([]rune{r}, s.buf[s.pos:]...)...)
+	s.pos++
+}
+
+func (s *lineState) backspace() {
+	if s.pos > 0 {
+		s.buf = append(s.buf[:s.pos-1], s.buf[s.pos:]...)
+		s.pos--
+	}
+}
+
+func (s *lineState) delete() {
+	if s.pos < len(s.buf) {
+		s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+	}
+}
+
+// deleteWord deletes the word before the cursor and the spaces after it.
+func (s *lineState) deleteWord() {
+	start := s.pos
+	for start > 0 && unicode.IsSpace(s.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(s.buf[start-1]) {
+		start--
+	}
+	s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+	s.pos = start
+}
+
+func (s *lineState) left() {
+	if s.pos > 0 {
+		s.pos--
+	}
+}
+
+func (s *lineState) right() {
+	if s.pos < len(s.buf) {
+		s.pos++
+	}
+}
+
+// complete completes the identifier that ends at pos in line with the
+// longest prefix shared by the names starting with it. It returns the new
+// line and cursor position, and the matching names, sorted.
+func complete(line []rune, pos int, names []string) ([]rune, int, []string) {
+	start := pos
+	for start > 0 && isIdentifierRune(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+	if prefix == "" || unicode.IsDigit(line[start]) {
+		return line, pos, nil
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+	sort.Strings(candidates)
+
+	common := []rune(candidates[0])
+	for _, c := range candidates[1:] {
+		common = commonPrefix(common, []rune(c))
+	}
+	insert := common[len([]rune(prefix)):]
+
+	completed := append(append(append([]rune{}, line[:pos]...), insert...), line[pos:]...)
+	return completed, pos + len(insert), candidates
+}
+
+func commonPrefix(a, b []rune) []rune {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
+
+// isIdentifierRune reports whether r may appear in an identifier.
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || unicode.In(r, unicode.Mn, unicode.Mc)
+}
@@ -0,0 +1,94 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HISTORY_FILE is the file in the home directory where the REPL keeps the
+// lines typed into it. The BANGU_HISTORY environment variable names a
+// different file; set it to the empty string to keep no history.
+const HISTORY_FILE = ".bangu_history"
+
+// maxHistory is the number of lines of history kept.
+const maxHistory = 1000
+
+// history is the list of lines typed into the REPL, oldest first, saved to
+// a file so that it survives across sessions.
+type history struct {
+	entries []string
+	path    string // where entries are saved; empty to not save them
+}
+
+// historyPath returns the file the history is saved to, or "" if there is
+// none.
+func historyPath() string {
+	if path, ok := os.LookupEnv("BANGU_HISTORY"); ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+// loadHistory reads the history saved at path. A missing or unreadable file
+// gives an empty history.
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.entries = append(h.entries, scanner.Text())
+	}
+
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		h.save()
+	}
+	return h
+}
+
+// add appends line to the history and to the history file, unless it is
+// blank or repeats the previous entry.
+func (h *history) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(line + "\n")
+}
+
+// save rewrites the history file with the current entries.
+func (h *history) save() {
+	data := strings.Join(h.entries, "\n") + "\n"
+	os.WriteFile(h.path, []byte(data), 0o600)
+}
@@ -1,44 +1,129 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 
 	"bangu/lexer"
 	"bangu/object"
 	"bangu/parser"
+	"bangu/token"
 
 	"bangu/evaluator"
 )
 
 const PROMPT = ">> "
 
+// CONTINUATION_PROMPT is shown instead of PROMPT while the input so far is
+// incomplete, such as a function whose closing brace is yet to be typed.
+const CONTINUATION_PROMPT = ".. "
+
+// Options configures a REPL session.
+type Options struct {
+	// Journal names a file that records the session's inputs as they are
+	// evaluated, so that a session ended by a crash can be restored. If the
+	// file holds a journal when the session starts, it is restored; it is
+	// removed when the session ends normally. Empty means no journal.
+	Journal string
+
+	// Err receives the parser and runtime errors, so they can be told
+	// apart from values. Nil means they go to the output with the values.
+	Err io.Writer
+}
+
+// Summary describes a finished REPL session.
+type Summary struct {
+	Inputs        int // the number of inputs evaluated
+	RuntimeErrors int // the number of inputs that failed with a runtime error
+}
+
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	StartWithOptions(in, out, Options{})
+}
+
+// StartWithOptions runs a REPL session configured by opts and returns its
+// summary once the input ends.
+func StartWithOptions(in io.Reader, out io.Writer, opts Options) Summary {
+	s := &session{
+		env:     object.NewEnvironment(),
+		out:     out,
+		errOut:  opts.Err,
+		sources: make(map[string]string),
+		journal: opts.Journal,
+	}
+	if s.errOut == nil {
+		s.errOut = out
+	}
+	lines := newLineReader(in, out, func() []string {
+		return append(s.env.Names(), evaluator.BuiltinNames()...)
+	})
+	if s.journal != "" {
+		s.restoreJournal()
+	}
+	source := ""
 
 	for {
-		fmt.Print(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
-			return // EOF or error
+		prompt := PROMPT
+		if source != "" {
+			prompt = CONTINUATION_PROMPT
+		}
+		line, err := lines.readLine(prompt)
+		if err == errInterrupted {
+			source = ""
+			continue
+		}
+		if err != nil {
+			if source != "" {
+				// Report whatever was left unfinished.
+				io.WriteString(out, "\n")
+				s.eval(source)
+			}
+			if s.journal != "" {
+				os.Remove(s.journal)
+			}
+			if n := s.summary.RuntimeErrors; n > 0 {
+				fmt.Fprintf(s.errOut, "\n%d of %d inputs failed with a runtime error\n", n, s.summary.Inputs)
+			}
+			return s.summary // EOF or error
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
+		if source == "" && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			s.runCommand(line)
+			continue
+		}
 
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		source += line + "\n"
+		if incomplete(source) {
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+		s.eval(source)
+		source = ""
+	}
+}
+
+// incomplete reports whether source needs more lines: whether it leaves a
+// paren, bracket, brace or string interpolation open, or ends inside a
+// string or block comment.
+func incomplete(source string) bool {
+	l := lexer.New(source)
+	depth := 0
+
+	for {
+		tok := l.NextToken()
+		switch tok.Type {
+		case token.EOF:
+			return depth > 0
+		case token.LPAREN, token.LBRACKET, token.LBRACE, token.TEMPLATE_HEAD:
+			depth++
+		case token.RPAREN, token.RBRACKET, token.RBRACE, token.TEMPLATE_TAIL:
+			depth--
+		case token.ILLEGAL:
+			if strings.HasPrefix(tok.Literal, "unterminated") && tok.Span.End.Offset == len(source) {
+				return true
+			}
 		}
 	}
 }
@@ -56,11 +141,96 @@ const BANGU_FACE = `
   (____________)
 `
 
-func printParserErrors(out io.Writer, errors []string) {
+func printParserErrors(out io.Writer, source string, diagnostics []parser.Diagnostic) {
 	io.WriteString(out, BANGU_FACE)
 	io.WriteString(out, "Woops! We ran into some bangu business here!\n")
 	io.WriteString(out, " parser errors:\n")
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+	printDiagnostics(out, "\t", source, diagnostics)
+}
+
+// PrintRuntimeError writes err with its position, followed by the line of
+// source it arose in with a caret underneath, as PrintDiagnostics does for
+// parser errors, and the calls it returned from.
+func PrintRuntimeError(out io.Writer, source string, err *object.Error) {
+	if err.Span.Start.IsValid() {
+		io.WriteString(out, err.Span.Start.String()+": runtime error: "+err.Message+"\n")
+		if source != "" {
+			printSourceContext(out, "\t", source, err.Span)
+		}
+	} else {
+		io.WriteString(out, "runtime error: "+err.Message+"\n")
 	}
+	printStack(out, err.Stack)
+}
+
+// maxFrames is the number of calls shown in a stack trace before the
+// middle of it, usually deep recursion, is left out.
+const maxFrames = 20
+
+// printStack writes the calls an error returned from, innermost first.
+func printStack(out io.Writer, stack []object.Frame) {
+	for i, frame := range stack {
+		if len(stack) > maxFrames && i == maxFrames/2 {
+			fmt.Fprintf(out, "\t... %d more calls ...\n", len(stack)-maxFrames)
+		}
+		if len(stack) > maxFrames && i >= maxFrames/2 && i < len(stack)-maxFrames/2 {
+			continue
+		}
+		io.WriteString(out, "\t"+frame.String()+"\n")
+	}
+}
+
+// PrintDiagnostics writes each diagnostic followed by the source line it
+// points at and its hint, if any. It is meant for reporting errors in a
+// whole program, as when running a script.
+func PrintDiagnostics(out io.Writer, source string, diagnostics []parser.Diagnostic) {
+	printDiagnostics(out, "", source, diagnostics)
+}
+
+func printDiagnostics(out io.Writer, indent, source string, diagnostics []parser.Diagnostic) {
+	for _, d := range diagnostics {
+		io.WriteString(out, indent+d.String()+"\n")
+		printSourceContext(out, indent+"\t", source, d.Span)
+		if d.Hint != "" {
+			io.WriteString(out, indent+"\thint: "+d.Hint+"\n")
+		}
+	}
+}
+
+// printSourceContext writes the source line containing span.Start with a
+// caret underneath the spanned characters, each line preceded by indent.
+func printSourceContext(out io.Writer, indent, source string, span token.Span) {
+	offset := span.Start.Offset
+	if !span.Start.IsValid() || offset > len(source) {
+		return
+	}
+
+	lineStart := strings.LastIndexByte(source[:offset], '\n') + 1
+	lineEnd := strings.IndexByte(source[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(source)
+	} else {
+		lineEnd += offset
+	}
+	line := source[lineStart:lineEnd]
+
+	// Keep tabs from the line's prefix so the caret lines up with the text,
+	// and pad by display width, since the vowel signs of Bangla take no
+	// column of their own.
+	var marker strings.Builder
+	for _, ch := range source[lineStart:offset] {
+		if ch == '\t' {
+			marker.WriteByte('\t')
+		} else {
+			marker.WriteString(strings.Repeat(" ", displayWidth([]rune{ch})))
+		}
+	}
+	width := 1
+	if span.End.Line == span.Start.Line && span.End.Offset > offset && span.End.Offset <= len(source) {
+		width = max(displayWidth([]rune(source[offset:span.End.Offset])), 1)
+	}
+	marker.WriteString(strings.Repeat("^", width))
+
+	io.WriteString(out, indent+line+"\n")
+	io.WriteString(out, indent+marker.String()+"\n")
 }
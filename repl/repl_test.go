@@ -0,0 +1,440 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bangu/object"
+	"bangu/token"
+)
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"let add = fn(a, b) {", true},
+		{"let add = fn(a, b) {\n  a + b\n", true},
+		{"let add = fn(a, b) {\n  a + b\n};", false},
+		{"add(1,", true},
+		{"[1, 2,\n", true},
+		{"{\"a\": 1", true},
+		{"\"unterminated", true},
+		{"\"line one\nline two\"", false},
+		{"`raw\n", true},
+		{"\"${ [1,", true},
+		{"\"${ x }\"", false},
+		{"/* a comment", true},
+		{"/* a comment */ 5", false},
+		{"1 @", false},
+		{"let x = 5; }", false},
+		{")(", false},
+	}
+
+	for _, tt := range tests {
+		if got := incomplete(tt.input); got != tt.expected {
+			t.Errorf("incomplete(%q) = %t, want %t", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestStartContinuesIncompleteInput(t *testing.T) {
+	input := "let add = fn(a, b) {\n  a + b\n};\nadd(1,\n 2)\n\"two\nlines\"\n"
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := ">> .. .. null\n>> .. 3\n>> .. two\nlines\n>> "
+	if out.String() != expected {
+		t.Errorf("wrong output.\nwant=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestComplete(t *testing.T) {
+	names := []string{"len", "last", "let_me", "counter", "নাম", "len"}
+
+	tests := []struct {
+		line, expectedLine string
+		pos, expectedPos   int
+		expectedCandidates []string
+	}{
+		{"coun", "counter", 4, 7, []string{"counter"}},
+		{"1 + coun + 2", "1 + counter + 2", 8, 11, []string{"counter"}},
+		{"l", "l", 1, 1, []string{"last", "len", "let_me"}},
+		{"le", "le", 2, 2, []string{"len", "let_me"}},
+		{"let_", "let_me", 4, 6, []string{"let_me"}},
+		{"না", "নাম", 2, 3, []string{"নাম"}},
+		{"x", "x", 1, 1, nil},
+		{"1 + ", "1 + ", 4, 4, nil},
+	}
+
+	for _, tt := range tests {
+		line, pos, candidates := complete([]rune(tt.line), tt.pos, names)
+		if string(line) != tt.expectedLine || pos != tt.expectedPos {
+			t.Errorf("complete(%q, %d) = %q, %d, want %q, %d",
+				tt.line, tt.pos, string(line), pos, tt.expectedLine, tt.expectedPos)
+		}
+		if strings.Join(candidates, " ") != strings.Join(tt.expectedCandidates, " ") {
+			t.Errorf("complete(%q, %d) candidates = %v, want %v",
+				tt.line, tt.pos, candidates, tt.expectedCandidates)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedPos  int
+		expectedNext rune
+	}{
+		{"\x1b[D", 1, 0},
+		{"\x1b[Dz", 1, 'z'},
+		{"\x1bOH", 0, 0},
+		{"\x1b[3~", 2, 0},
+		{"\x1bx", 2, 'x'},
+		{"\x1b\x1b[D", 2, '\x1b'},
+	}
+
+	for _, tt := range tests {
+		e := &editor{in: bufio.NewReader(strings.NewReader(tt.input))}
+		s := &lineState{buf: []rune("ab"), pos: 2}
+		e.in.ReadRune() // the ESC readLine has read
+		e.escape(s)
+
+		if s.pos != tt.expectedPos {
+			t.Errorf("%q: cursor at %d, want %d", tt.input, s.pos, tt.expectedPos)
+		}
+		if next, _, _ := e.in.ReadRune(); next != tt.expectedNext {
+			t.Errorf("%q: next key %q, want %q", tt.input, next, tt.expectedNext)
+		}
+	}
+}
+
+func TestEscapeAlone(t *testing.T) {
+	// Nothing follows the ESC yet, as when the Esc key is pressed, so
+	// escape must return without waiting for the next key.
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("\x1b"))
+
+	e := &editor{in: bufio.NewReader(r)}
+	e.in.ReadRune()
+	done := make(chan struct{})
+	go func() {
+		e.escape(&lineState{})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("escape waits for a key after a lone ESC")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := loadHistory(path)
+	for _, line := range []string{"let x = 1;", "x", "x", "   ", "x + 1"} {
+		h.add(line)
+	}
+
+	expected := []string{"let x = 1;", "x", "x + 1"}
+	reloaded := loadHistory(path)
+	if strings.Join(reloaded.entries, "|") != strings.Join(expected, "|") {
+		t.Errorf("wrong history. want=%q, got=%q", expected, reloaded.entries)
+	}
+
+	for i := 0; i < maxHistory+10; i++ {
+		h.add(fmt.Sprint(i))
+	}
+	reloaded = loadHistory(path)
+	if len(reloaded.entries) != maxHistory || reloaded.entries[0] != "10" {
+		t.Errorf("history not trimmed to %d entries: got %d starting with %q",
+			maxHistory, len(reloaded.entries), reloaded.entries[0])
+	}
+}
+
+func TestCommands(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "lib.bangu")
+	if err := os.WriteFile(script, []byte("let double = fn(x) { x * 2 };\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 5;\nlet f = fn(a, b = 2) { a + b };\n:env\n", []string{"f  FUNCTION  fn(a, b = 2)\n", "x  INTEGER   5\n"}},
+		{":type 1 + 2.5\n:type [1]\n:type y\n", []string{"FLOAT\n", "ARRAY\n", "<input 3>:1:1: runtime error: identifier not found: y\n\ty\n\t^\n"}},
+		{":ast -x\n", []string{"PrefixExpression 1:1\n", "Operator: \"-\"\n", "Right: Identifier 1:2\n"}},
+		{":ast 1 +\n", []string{"parser errors:"}},
+		{":tokens x = 1\n", []string{"1:1  IDENT  \"x\"\n1:3  =      \"=\"\n1:5  INT    \"1\"\n"}},
+		{":time 6 * 7\n", []string{"42\ntook "}},
+		{":load " + script + "\ndouble(21)\n", []string{"42\n"}},
+		{":load /no/such/file.bangu\n", []string{"cannot load /no/such/file.bangu"}},
+		{"let x = 5;\n:reset\n:env\nx\n", []string{"no variables are bound\n", "identifier not found: x"}},
+		{":help\n", []string{":env ", ":type EXPR  ", ":load FILE  "}},
+		{":bogus\n", []string{"unknown command :bogus"}},
+		{":type\n:env x\n", []string{"usage: :type EXPR\n", "usage: :env takes no argument\n"}},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out)
+		for _, want := range tt.expected {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("output of %q does not contain %q. got=%q", tt.input, want, out.String())
+			}
+		}
+	}
+}
+
+func TestSaveAndLoadSession(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "session.bangu")
+	lib := filepath.Join(dir, "lib.bangu")
+	if err := os.WriteFile(lib, []byte("#!/usr/bin/env bangu\nlet half = fn(x) { x / 2 };\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := "let x = 5;\nlet y = x +;\nz\nlet f = fn(a) {\n  a * x\n};\n:load " + lib + "\n:type f(2)\n:save " + saved + "\n"
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+	if !strings.Contains(out.String(), "saved 4 inputs to "+saved) {
+		t.Errorf("wrong output: %q", out.String())
+	}
+
+	expected := "// :input\nlet x = 5;\n// :input\nlet f = fn(a) {\n  a * x\n};\n" +
+		"// :input\n// :load " + lib + "\nlet half = fn(x) { x / 2 };\n// :input\nf(2)\n"
+	script, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(script) != expected {
+		t.Errorf("wrong script.\nwant=%q\ngot=%q", expected, string(script))
+	}
+
+	out.Reset()
+	Start(strings.NewReader("let x = 1;\n:load-session "+saved+"\nhalf(f(4))\n:env\n"), &out)
+	for _, want := range []string{"restored session from " + saved, ">> 10\n", "x     INTEGER   5\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q. got=%q", want, out.String())
+		}
+	}
+}
+
+func TestSaveSessionWithReturn(t *testing.T) {
+	saved := filepath.Join(t.TempDir(), "session.bangu")
+
+	// Each return ends only the input it is in, so replaying the session
+	// must run the inputs after it.
+	input := "let a = 1;\nreturn a;\nif (true) { return 5 }; let skipped = 0;\nlet b = 2;\n:save " + saved + "\n"
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	expected := "// :input\nlet a = 1;\n// :input\nreturn a;\n// :input\nif (true) { return 5 }; let skipped = 0;\n// :input\nlet b = 2;\n"
+	script, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(script) != expected {
+		t.Errorf("wrong script.\nwant=%q\ngot=%q", expected, string(script))
+	}
+
+	out.Reset()
+	Start(strings.NewReader(":load-session "+saved+"\n:env\n:save "+saved+"\n"), &out)
+	if want := "a  INTEGER  1\nb  INTEGER  2\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("output does not contain %q. got=%q", want, out.String())
+	}
+	if strings.Contains(out.String(), "skipped") {
+		t.Errorf("output contains the input skipped by return. got=%q", out.String())
+	}
+
+	resaved, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resaved) != expected {
+		t.Errorf("wrong script saved after restoring.\nwant=%q\ngot=%q", expected, string(resaved))
+	}
+}
+
+func TestLoadSessionKeepsSessionOnError(t *testing.T) {
+	broken := filepath.Join(t.TempDir(), "broken.bangu")
+	if err := os.WriteFile(broken, []byte("let a = 1;\nmissing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	Start(strings.NewReader("let x = 1;\n:load-session "+broken+"\nx\na\n"), &out)
+	for _, want := range []string{"identifier not found: missing", ">> 1\n", "identifier not found: a"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q. got=%q", want, out.String())
+		}
+	}
+}
+
+func TestJournal(t *testing.T) {
+	journal := filepath.Join(t.TempDir(), "journal.bangu")
+	if err := os.WriteFile(journal, []byte("let crashed = 42;\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session is read line by line, so the journal can be checked
+	// while it is still running.
+	r, w := io.Pipe()
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		StartWithOptions(r, &out, Options{Journal: journal})
+		close(done)
+	}()
+
+	io.WriteString(w, "let more = crashed + 1;\n")
+	io.WriteString(w, "oops\n")
+	io.WriteString(w, ":type more\n") // the prompt for this line shows the previous lines are done
+
+	journaled, err := os.ReadFile(journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "// :input\nlet crashed = 42;\n// :input\nlet more = crashed + 1;\n"
+	if string(journaled) != expected {
+		t.Errorf("wrong journal.\nwant=%q\ngot=%q", expected, string(journaled))
+	}
+
+	w.Close()
+	<-done
+	if !strings.Contains(out.String(), "restored the unfinished session journaled in "+journal) {
+		t.Errorf("journal not restored. got=%q", out.String())
+	}
+	if _, err := os.Stat(journal); !os.IsNotExist(err) {
+		t.Errorf("journal not removed at the end of the session: %v", err)
+	}
+}
+
+func TestJournalThatFailsToRestore(t *testing.T) {
+	journal := filepath.Join(t.TempDir(), "journal.bangu")
+	broken := "let a = 1;\nmissing\n"
+	if err := os.WriteFile(journal, []byte(broken), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w := io.Pipe()
+	var out, errOut bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		StartWithOptions(r, &out, Options{Journal: journal, Err: &errOut})
+		close(done)
+	}()
+
+	io.WriteString(w, "let b = 2;\n")
+	io.WriteString(w, "b\n") // the prompt for this line shows the previous line is done
+
+	journaled, err := os.ReadFile(journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "// :input\nlet b = 2;\n"; string(journaled) != expected {
+		t.Errorf("wrong journal.\nwant=%q\ngot=%q", expected, string(journaled))
+	}
+
+	w.Close()
+	<-done
+	failed, err := os.ReadFile(journal + ".failed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(failed) != broken {
+		t.Errorf("wrong failed journal.\nwant=%q\ngot=%q", broken, string(failed))
+	}
+	if want := "moved it to " + journal + ".failed\n"; !strings.Contains(errOut.String(), want) {
+		t.Errorf("errors do not contain %q. got=%q", want, errOut.String())
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	input := "let f = fn(a) {\n  a / 0\n};\nf(1)\n1 + 1\nlet x = ;\n"
+	var out, errOut bytes.Buffer
+	summary := StartWithOptions(strings.NewReader(input), &out, Options{Err: &errOut})
+
+	if expected := ">> .. .. null\n>> >> 2\n>> >> "; out.String() != expected {
+		t.Errorf("wrong output.\nwant=%q\ngot=%q", expected, out.String())
+	}
+
+	// The error is shown in the input defining f, not the one calling it.
+	expected := "<input 1>:2:3: runtime error: division by zero\n\t  a / 0\n\t  ^^^^^\n"
+	if !strings.HasPrefix(errOut.String(), expected) {
+		t.Errorf("wrong runtime error.\nwant=%q\ngot=%q", expected, errOut.String())
+	}
+	for _, want := range []string{"parser errors:", "1 of 4 inputs failed with a runtime error\n"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("errors do not contain %q. got=%q", want, errOut.String())
+		}
+	}
+
+	if summary.Inputs != 4 || summary.RuntimeErrors != 1 {
+		t.Errorf("wrong summary: %+v", summary)
+	}
+}
+
+func TestPrintRuntimeErrorInBangla(t *testing.T) {
+	// The nukta in each বয়স combines with the letter before it and takes no
+	// column, so the carets must sit under সীমা rather than to the right.
+	var out, errOut bytes.Buffer
+	StartWithOptions(strings.NewReader("let বয়স = 1; বয়স + সীমা\n"), &out, Options{Err: &errOut})
+
+	expected := "<input 1>:1:22: runtime error: identifier not found: সীমা\n" +
+		"\tlet বয়স = 1; বয়স + সীমা\n" +
+		"\t                   ^^^^\n"
+	if !strings.HasPrefix(errOut.String(), expected) {
+		t.Errorf("wrong runtime error.\nwant=%q\ngot=%q", expected, errOut.String())
+	}
+}
+
+func TestPrintRuntimeErrorWithoutSpan(t *testing.T) {
+	err := &object.Error{
+		Message: "division by zero",
+		Stack:   []object.Frame{{Function: "f", Call: token.Position{Line: 3, Column: 1}}},
+	}
+
+	var out bytes.Buffer
+	PrintRuntimeError(&out, "", err)
+	if expected := "runtime error: division by zero\n\tin f, called at 3:1\n"; out.String() != expected {
+		t.Errorf("wrong output.\nwant=%q\ngot=%q", expected, out.String())
+	}
+}
+
+func TestPrintStack(t *testing.T) {
+	var stack []object.Frame
+	for i := 1; i <= maxFrames+5; i++ {
+		stack = append(stack, object.Frame{Function: "f", Call: token.Position{Line: i, Column: 1}})
+	}
+
+	var out bytes.Buffer
+	printStack(&out, stack)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+
+	if len(lines) != maxFrames+1 {
+		t.Fatalf("wrong number of lines. want=%d, got=%d: %q", maxFrames+1, len(lines), lines)
+	}
+	expected := map[int]string{
+		0:             "\tin f, called at 1:1",
+		maxFrames / 2: "\t... 5 more calls ...",
+		maxFrames:     "\tin f, called at 25:1",
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("wrong line %d. want=%q, got=%q", i, want, lines[i])
+		}
+	}
+}
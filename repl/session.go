@@ -0,0 +1,212 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"bangu/evaluator"
+	"bangu/lexer"
+	"bangu/object"
+	"bangu/parser"
+)
+
+// session is the state of one REPL session.
+type session struct {
+	env    *object.Environment
+	out    io.Writer
+	errOut io.Writer // where parser and runtime errors are reported
+
+	// sources maps the name of each input evaluated to its text, so that
+	// an error can be shown in the input it arose in, which may be an
+	// earlier one than the input that ran into it.
+	sources map[string]string
+	summary Summary
+
+	// inputs are the inputs evaluated without error since the session
+	// started or was last reset. Run in order as a script, they rebuild the
+	// session's variables.
+	inputs []string
+
+	journal string // the file inputs are recorded in as they run, if any
+}
+
+// eval evaluates source and prints its value.
+func (s *session) eval(source string) {
+	evaluated, ok := s.run(source)
+	if ok && evaluated != nil {
+		io.WriteString(s.out, evaluated.Inspect())
+		io.WriteString(s.out, "\n")
+	}
+}
+
+// run evaluates source typed into the session, recording it if it
+// succeeds.
+func (s *session) run(source string) (object.Object, bool) {
+	evaluated, ok := s.evaluate("", source)
+	if ok {
+		s.record(source)
+	}
+	return evaluated, ok
+}
+
+// evaluate parses and evaluates source in the session's environment. The
+// source is named after the file it was read from or, when name is empty,
+// after its number in the session, as in "<input 3>". Parser and runtime
+// errors are reported to the error stream, and make evaluate return false.
+func (s *session) evaluate(name, source string) (object.Object, bool) {
+	s.summary.Inputs++
+	if name == "" {
+		name = fmt.Sprintf("<input %d>", s.summary.Inputs)
+	}
+	return s.execute(name, source)
+}
+
+// execute parses and evaluates source, named name, as evaluate does, but
+// without counting it as an input of the session.
+func (s *session) execute(name, source string) (object.Object, bool) {
+	s.sources[name] = source
+
+	l := lexer.NewFile(name, source)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.errOut, source, p.Diagnostics())
+		return nil, false
+	}
+
+	evaluated := evaluator.Eval(program, s.env)
+	if err, ok := evaluated.(*object.Error); ok {
+		s.summary.RuntimeErrors++
+		PrintRuntimeError(s.errOut, s.sources[err.Span.Start.Filename], err)
+		return nil, false
+	}
+	return evaluated, true
+}
+
+// inputMarker starts each input in a saved session or journal, so that
+// the inputs can be replayed one at a time, as they were typed. A return
+// statement then ends only the input it is in, rather than the replay.
+const inputMarker = "// :input\n"
+
+// record adds source to the session's inputs and its journal.
+func (s *session) record(source string) {
+	if !strings.HasSuffix(source, "\n") {
+		source += "\n"
+	}
+	s.inputs = append(s.inputs, source)
+
+	if s.journal == "" {
+		return
+	}
+	f, err := os.OpenFile(s.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		fmt.Fprintf(s.errOut, "cannot write journal: %s\n", err)
+		return
+	}
+	defer f.Close()
+	f.WriteString(inputMarker + source)
+}
+
+// script returns the session's inputs as a script, each starting with
+// inputMarker.
+func (s *session) script() string {
+	var b strings.Builder
+	for _, input := range s.inputs {
+		b.WriteString(inputMarker + input)
+	}
+	return b.String()
+}
+
+// splitInputs splits a script saved by script into its inputs. A script
+// without input markers, such as one written by hand, is a single input.
+func splitInputs(script string) []string {
+	var inputs []string
+	for _, input := range strings.Split("\n"+script, "\n"+inputMarker) {
+		if strings.TrimSpace(input) != "" {
+			inputs = append(inputs, strings.TrimPrefix(input, "\n"))
+		}
+	}
+	return inputs
+}
+
+// clear forgets the session's variables and inputs.
+func (s *session) clear() {
+	s.env = object.NewEnvironment()
+	s.inputs = nil
+	if s.journal != "" {
+		os.Remove(s.journal)
+	}
+}
+
+func (s *session) save(path string) {
+	if err := os.WriteFile(path, []byte(s.script()), 0o644); err != nil {
+		fmt.Fprintf(s.errOut, "cannot save %s: %s\n", path, err)
+		return
+	}
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.inputs), path)
+}
+
+// loadSession replaces the session with the one saved in path.
+func (s *session) loadSession(path string) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(s.errOut, "cannot load %s: %s\n", path, err)
+		return
+	}
+	if s.restore(path, string(source)) {
+		fmt.Fprintf(s.out, "restored session from %s\n", path)
+	}
+}
+
+// restoreJournal restores the session left in the journal by a session
+// that did not end normally. A journal that cannot be restored is moved
+// aside to FILE.failed, so that the session starts a new journal rather
+// than adding to one that would fail again.
+func (s *session) restoreJournal() {
+	source, err := os.ReadFile(s.journal)
+	if err != nil || len(source) == 0 {
+		return
+	}
+	if s.restore(s.journal, string(source)) {
+		fmt.Fprintf(s.out, "restored the unfinished session journaled in %s\n", s.journal)
+		return
+	}
+
+	failed := s.journal + ".failed"
+	if err := os.Rename(s.journal, failed); err != nil {
+		fmt.Fprintf(s.errOut, "cannot move aside the journal: %s; the session is not journaled\n", err)
+		s.journal = ""
+		return
+	}
+	fmt.Fprintf(s.errOut, "could not restore the session journaled in %s; moved it to %s\n", s.journal, failed)
+}
+
+// restore replaces the session's variables and inputs with those of the
+// session saved as source, replaying its inputs one at a time and
+// reporting whether they ran without error. The session is left as it was
+// if they did not.
+func (s *session) restore(name, source string) bool {
+	env := s.env
+	s.env = object.NewEnvironment()
+	s.summary.Inputs++
+
+	inputs := splitInputs(source)
+	for i, input := range inputs {
+		if _, ok := s.execute(fmt.Sprintf("%s (input %d)", name, i+1), input); !ok {
+			s.env = env
+			return false
+		}
+	}
+
+	s.inputs = nil
+	if s.journal != "" {
+		os.Remove(s.journal)
+	}
+	for _, input := range inputs {
+		s.record(input)
+	}
+	return true
+}
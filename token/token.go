@@ -1,10 +1,59 @@
 package token
 
+import (
+	"fmt"
+	"strings"
+)
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Span    Span // The source range the token was read from.
+
+	// Leading holds the comments between the previous token and this one,
+	// so tools such as formatters can preserve them.
+	Leading []Comment
+}
+
+// Comment is a line or block comment. Comments are not tokens of their own;
+// the lexer attaches them to the token that follows.
+type Comment struct {
+	Text string // The comment text, including its delimiters.
+	Span Span
+}
+
+// Position describes a location in the source text.
+type Position struct {
+	Filename string // Name of the source file, if any.
+	Offset   int    // Byte offset, starting at 0.
+	Line     int    // Line number, starting at 1.
+	Column   int    // Column number, starting at 1.
+}
+
+// IsValid reports whether the position has been set.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as "file:line:column", or "line:column" when
+// there is no file name.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span is a half-open range of source text: Start is the first character and
+// End is the position immediately after the last one.
+type Span struct {
+	Start Position
+	End   Position
 }
 
 const (
@@ -14,6 +63,14 @@ const (
 	// Identifiers + literals
 	IDENT = "IDENT"
 	INT   = "INT"
+	FLOAT = "FLOAT"
+
+	// An interpolated string such as "a${x}b${y}c" is lexed as a
+	// TEMPLATE_HEAD "a", the tokens of x, a TEMPLATE_MIDDLE "b", the tokens
+	// of y and a TEMPLATE_TAIL "c". A string without ${ is a plain STRING.
+	TEMPLATE_HEAD   = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   = "TEMPLATE_TAIL"
 
 	// Operators
 	ASSIGN   = "="
@@ -22,9 +79,29 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+	POWER    = "**"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
 
-	LT = "<"
-	GT = ">"
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
+
+	AND = "&&"
+	OR  = "||"
+
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	AND_NOT   = "&^"
+	SHL       = "<<"
+	SHR       = ">>"
 
 	EQ     = "=="
 	NOT_EQ = "!="
@@ -40,6 +117,7 @@ const (
 	LBRACKET = "["
 	RBRACKET = "]"
 	COLON    = ":"
+	ELLIPSIS = "..."
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -49,18 +127,92 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
 
 	STRING = "STRING"
 )
 
-var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+// Vocabulary gives the spelling of each keyword in one language.
+type Vocabulary map[TokenType]string
+
+// English is the original keyword set.
+var English = Vocabulary{
+	FUNCTION: "fn",
+	LET:      "let",
+	TRUE:     "true",
+	FALSE:    "false",
+	IF:       "if",
+	ELSE:     "else",
+	RETURN:   "return",
+	WHILE:    "while",
+	FOR:      "for",
+	IN:       "in",
+	BREAK:    "break",
+	CONTINUE: "continue",
+}
+
+// Bangla spells the keywords in Bangla, so that a program can read
+// `ধরি বয়স = ২০;` or `যদি (বয়স > ১৮) { সত্য } নাহলে { মিথ্যা }`.
+var Bangla = Vocabulary{
+	FUNCTION: "কাজ",
+	LET:      "ধরি",
+	TRUE:     "সত্য",
+	FALSE:    "মিথ্যা",
+	IF:       "যদি",
+	ELSE:     "নাহলে",
+	RETURN:   "ফেরত",
+	WHILE:    "যতক্ষণ",
+	FOR:      "প্রতিটি",
+	IN:       "মধ্যে",
+	BREAK:    "থামো",
+	CONTINUE: "চালাও",
+}
+
+// Vocabularies maps the name of each language, as in bangu -lang bn, to its
+// keywords.
+var Vocabularies = map[string]Vocabulary{
+	"en": English,
+	"bn": Bangla,
+}
+
+// keywords maps the spelling of every keyword that LookupIdent recognizes
+// to its token type.
+var keywords = keywordsOf(English)
+
+// UseVocabularies sets the languages whose keywords LookupIdent recognizes.
+// By default only the English keywords are, so that Bangla words such as
+// ফেরত remain free to name variables. Give several vocabularies to mix
+// their keywords in one program.
+func UseVocabularies(vocabularies ...Vocabulary) {
+	keywords = keywordsOf(vocabularies...)
+}
+
+// ParseVocabularies returns the vocabularies named in a comma-separated
+// list of languages, such as "en" or "en,bn".
+func ParseVocabularies(list string) ([]Vocabulary, error) {
+	var vocabularies []Vocabulary
+	for _, name := range strings.Split(list, ",") {
+		v, ok := Vocabularies[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown keyword language %q (want en, bn or en,bn)", name)
+		}
+		vocabularies = append(vocabularies, v)
+	}
+	return vocabularies, nil
+}
+
+func keywordsOf(vocabularies ...Vocabulary) map[string]TokenType {
+	kw := make(map[string]TokenType)
+	for _, v := range vocabularies {
+		for t, spelling := range v {
+			kw[spelling] = t
+		}
+	}
+	return kw
 }
 
 func LookupIdent(ident string) TokenType {